@@ -18,6 +18,11 @@ type Config struct {
 	Port        string `validate:"required"`
 	Host        string `validate:"required"`
 	AllowOrigin string
+	H2C         bool   // 允许明文 HTTP/2，用于内部服务间通信
+	TLSCertFile string // 与 TLSKeyFile 同时设置时直接终止 TLS，证书更新后自动重新加载
+	TLSKeyFile  string
+	Mock        bool   // 返回示例响应而不执行业务逻辑，用于前端联调
+	RecordFile  string // 设置后将请求和响应录制到该 JSONL 文件，用于在本地重放排查问题
 
 	// S3 配置
 	S3Endpoint  string `validate:"required"`
@@ -44,6 +49,11 @@ func Load() (Config, error) {
 		Port:        getEnv("PORT", "8083", true),
 		Host:        getEnv("HOST", "0.0.0.0", true),
 		AllowOrigin: getEnv("ALLOW_ORIGIN", "", true),
+		H2C:         getEnv("H2C", "false", true) == "true",
+		TLSCertFile: getEnv("TLS_CERT_FILE", "", true),
+		TLSKeyFile:  getEnv("TLS_KEY_FILE", "", true),
+		Mock:        getEnv("MOCK", "false", true) == "true",
+		RecordFile:  getEnv("RECORD_FILE", "", true),
 
 		S3Endpoint:  getEnv("S3_ENDPOINT", "", true),
 		S3AccessKey: getEnv("S3_ACCESS_KEY", "", true),
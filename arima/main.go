@@ -4,6 +4,10 @@ package main
 import (
 	"fmt"
 	"log/slog"
+	"net/http"
+
+	"github.com/akagiyui/go-together/rest"
+	"github.com/akagiyui/go-together/rest/record"
 
 	"github.com/akagiyui/go-together/arima/config"
 	_ "github.com/akagiyui/go-together/arima/pkg/s3" // 初始化 S3 客户端
@@ -34,8 +38,28 @@ func main() {
 	}
 	slog.SetLogLoggerLevel(level)
 
+	runOptions := rest.RunOptions{
+		Addr:     fmt.Sprintf("%s:%s", cfg.Host, cfg.Port),
+		H2C:      cfg.H2C,
+		CertFile: cfg.TLSCertFile,
+		KeyFile:  cfg.TLSKeyFile,
+	}
+
+	// 录制请求，录制文件可以用 resttest.ReplayFile 在本地重放
+	if cfg.RecordFile != "" {
+		recorder, err := record.New(record.Options{Path: cfg.RecordFile})
+		if err != nil {
+			panic(err)
+		}
+		defer recorder.Close()
+		runOptions.ConfigureServer = func(srv *http.Server) {
+			srv.Handler = recorder.Handler(srv.Handler)
+		}
+		slog.Warn("request recording enabled", "file", cfg.RecordFile)
+	}
+
 	// 启动服务器
-	if err := s.Run(fmt.Sprintf("%s:%s", cfg.Host, cfg.Port)); err != nil {
+	if err := s.RunWithOptions(runOptions); err != nil {
 		panic(err)
 	}
 }
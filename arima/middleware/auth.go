@@ -2,10 +2,9 @@
 package middleware
 
 import (
-	"strings"
-
 	"github.com/akagiyui/go-together/common/model"
 	"github.com/akagiyui/go-together/rest"
+	"github.com/akagiyui/go-together/rest/auth"
 
 	"github.com/akagiyui/go-together/arima/config"
 	"github.com/akagiyui/go-together/arima/repo"
@@ -13,34 +12,30 @@ import (
 
 // AuthMiddleware 从请求头中获取 access_key，并验证其有效性
 func AuthMiddleware() rest.HandlerFunc {
-	return func(ctx *rest.Context) {
-		rawToken := ctx.Request.Header.Get("Authorization")
-		if accessKey, ok := strings.CutPrefix(rawToken, "Bearer "); ok {
-			accessKey = strings.TrimSpace(accessKey)
-			if accessKey == "" {
-				return
-			}
+	authenticator := auth.Bearer(auth.BearerToken(), func(accessKey string) (any, error) {
+		// 检查是否是管理 API Key
+		if auth.Equal(accessKey, config.GlobalConfig.ManageAPIKey) {
+			// 创建一个虚拟的管理员用户
+			return repo.User{
+				ID:          0,
+				Name:        "Admin",
+				IsActive:    true,
+				IsSuperuser: true,
+			}, nil
+		}
 
-			// 检查是否是管理 API Key
-			if accessKey == config.GlobalConfig.ManageAPIKey {
-				// 创建一个虚拟的管理员用户
-				adminUser := repo.User{
-					ID:          0,
-					Name:        "Admin",
-					IsActive:    true,
-					IsSuperuser: true,
-				}
-				ctx.Set("user", adminUser)
-				return
-			}
+		// 从数据库中查找用户
+		return repo.GetUserByAccessKey(accessKey)
+	})
 
-			// 从数据库中查找用户
-			user, err := repo.GetUserByAccessKey(accessKey)
-			if err != nil {
-				return
-			}
-			ctx.Set("user", user)
+	return func(ctx *rest.Context) {
+		// 凭证无效或查询用户失败时按匿名请求处理，由 RequireAuth 决定是否拒绝，公开路由不受影响
+		user, err := authenticator.Authenticate(ctx)
+		if err != nil {
+			return
 		}
+		auth.SetPrincipal(ctx, user)
+		ctx.Set("user", user)
 	}
 }
 
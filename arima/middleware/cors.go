@@ -11,7 +11,7 @@ func CorsMiddleware(allowOrigin string) rest.HandlerFunc {
 	return func(ctx *rest.Context) {
 		ctx.Response.Header("Access-Control-Allow-Origin", allowOrigin)
 		ctx.Response.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-		ctx.Response.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
+		ctx.Response.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, Idempotency-Key")
 		ctx.Response.Header("Access-Control-Allow-Credentials", "true")
 		ctx.Response.Header("Access-Control-Max-Age", "86400")
 
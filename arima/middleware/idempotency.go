@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/akagiyui/go-together/common/model"
+	"github.com/akagiyui/go-together/rest"
+	"github.com/akagiyui/go-together/rest/idempotency"
+
+	"github.com/akagiyui/go-together/arima/repo"
+)
+
+// idempotencyStore 所有创建类接口共用的幂等记录存储
+var idempotencyStore = idempotency.NewMemoryStore()
+
+// IdempotencyMiddleware 根据 Idempotency-Key 请求头避免客户端重试时重复创建资源
+// 需要放在 AuthMiddleware 之后，幂等键按用户隔离
+func IdempotencyMiddleware() rest.HandlerFunc {
+	return idempotency.Middleware(idempotency.Options{
+		Store: idempotencyStore,
+		Scope: func(ctx *rest.Context) string {
+			if user, ok := ctx.Get("user"); ok {
+				if user, ok := user.(repo.User); ok {
+					return strconv.FormatInt(user.ID, 10)
+				}
+			}
+			return ""
+		},
+		OnConflict: func(ctx *rest.Context) {
+			ctx.SetResult(model.Error(model.ErrConflict))
+		},
+		OnMismatch: func(ctx *rest.Context) {
+			ctx.SetResult(model.Error(model.ErrInputError, "Idempotency-Key was used with a different request"))
+		},
+		// 业务错误在响应封装钩子中才会转换为 HTTP 状态码，这里需要根据 ctx.Status 判断
+		ShouldStore: func(ctx *rest.Context) bool {
+			if ctx.StatusCode >= http.StatusInternalServerError {
+				return false
+			}
+			if ctx.Status == nil || ctx.Status == model.ErrSuccess {
+				return true
+			}
+			code, ok := ctx.Status.(model.BusinessCode)
+			return ok && model.HTTPStatus(code) < http.StatusInternalServerError
+		},
+	})
+}
@@ -9,11 +9,9 @@ import (
 	"github.com/akagiyui/go-together/rest"
 )
 
-// ResponseWrapperMiddleware 响应包装中间件
-func ResponseWrapperMiddleware() rest.HandlerFunc {
+// ResponseWrapperHook 响应包装钩子，通过 OnResponse 注册，在所有中间件之后执行
+func ResponseWrapperHook() rest.HandlerFunc {
 	return func(ctx *rest.Context) {
-		ctx.Next()
-
 		// 检测 ctx.Status
 		if ctx.Status != nil {
 			if ctx.Status != model.ErrSuccess {
@@ -0,0 +1,15 @@
+// Package mock 提供 mock 模式使用的示例响应文件，只在开启 mock 模式时读取
+//
+// 文件名为请求结构体的类型名加 .json，无法根据响应类型生成合适示例的接口（如返回 URL 的接口）在此提供示例
+package mock
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed fixtures
+var fixtures embed.FS
+
+// Fixtures 示例响应文件，用于 rest.MockOptions.Fixtures
+var Fixtures, _ = fs.Sub(fixtures, "fixtures")
@@ -32,23 +32,6 @@ func (Audio) TableName() string {
 	return "audio"
 }
 
-// GetAudioList 获取音频列表（分页）
-func GetAudioList(page, pageSize int) ([]Audio, int64, error) {
-	var audios []Audio
-	var total int64
-
-	if err := DB.Model(&Audio{}).Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
-
-	offset := (page - 1) * pageSize
-	if err := DB.Order("id").Offset(offset).Limit(pageSize).Find(&audios).Error; err != nil {
-		return nil, 0, err
-	}
-
-	return audios, total, nil
-}
-
 // GetAudioByID 根据ID获取音频
 func GetAudioByID(id int64) (Audio, error) {
 	var audio Audio
@@ -35,23 +35,6 @@ func (OriginAudio) TableName() string {
 	return "origin_audio"
 }
 
-// GetOriginAudioList 获取原始音频列表（分页）
-func GetOriginAudioList(page, pageSize int) ([]OriginAudio, int64, error) {
-	var audios []OriginAudio
-	var total int64
-
-	if err := DB.Model(&OriginAudio{}).Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
-
-	offset := (page - 1) * pageSize
-	if err := DB.Order("id DESC").Offset(offset).Limit(pageSize).Find(&audios).Error; err != nil {
-		return nil, 0, err
-	}
-
-	return audios, total, nil
-}
-
 // GetOriginAudioByID 根据ID获取原始音频
 func GetOriginAudioByID(id int64) (OriginAudio, error) {
 	var audio OriginAudio
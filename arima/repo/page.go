@@ -0,0 +1,121 @@
+package repo
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/akagiyui/go-together/common/model"
+	"github.com/akagiyui/go-together/rest"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// pageCursor 游标分页的游标内容
+type pageCursor struct {
+	ID int64 `json:"id"`
+}
+
+// FindPage 按照分页、排序和过滤参数查询，返回 model.PageData 或 model.CursorPageData
+// columns 为接口字段名到数据库列名的映射，需要覆盖 page 标签中允许排序和过滤的所有字段
+// 游标分页只支持按 id 排序，要求 T 带有 int64 类型的 ID 字段
+// 未知的排序或过滤字段、游标分页使用其他排序以及无效的游标均返回包装了 model.ErrInputError 的错误
+func FindPage[T any](db *gorm.DB, q rest.PageQuery, columns map[string]string) (any, error) {
+	query := db.Model(new(T))
+	for _, filter := range q.Filter {
+		column, ok := columns[filter.Field]
+		if !ok {
+			return nil, fmt.Errorf("%w: filter field %q has no column", model.ErrInputError, filter.Field)
+		}
+		query = applyFilter(query, column, filter)
+	}
+
+	if q.Mode == rest.PageByCursor {
+		return findCursorPage[T](query, q)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+	for _, sort := range q.Sort {
+		column, ok := columns[sort.Field]
+		if !ok {
+			return nil, fmt.Errorf("%w: sort field %q has no column", model.ErrInputError, sort.Field)
+		}
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: sort.Desc})
+	}
+	var list []T
+	if err := query.Offset(q.Offset()).Limit(q.Limit()).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return model.Page(total, list), nil
+}
+
+// findCursorPage 按 id 进行游标分页，多查询一条用于判断是否还有下一页
+func findCursorPage[T any](query *gorm.DB, q rest.PageQuery) (any, error) {
+	desc := false
+	if len(q.Sort) > 0 {
+		if q.Sort[0].Field != "id" || len(q.Sort) > 1 {
+			return nil, fmt.Errorf("%w: cursor pagination only supports sorting by id", model.ErrInputError)
+		}
+		desc = q.Sort[0].Desc
+	}
+
+	var cursor pageCursor
+	if ok, err := q.DecodeCursor(&cursor); err != nil {
+		return nil, fmt.Errorf("%w: %w", model.ErrInputError, err)
+	} else if ok {
+		if desc {
+			query = query.Where("id < ?", cursor.ID)
+		} else {
+			query = query.Where("id > ?", cursor.ID)
+		}
+	}
+
+	var list []T
+	err := query.Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}, Desc: desc}).Limit(q.Limit() + 1).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+
+	nextCursor := ""
+	if len(list) > q.Limit() {
+		list = list[:q.Limit()]
+		last := reflect.ValueOf(list[len(list)-1]).FieldByName("ID").Int()
+		nextCursor = rest.EncodeCursor(pageCursor{ID: last})
+	}
+	return model.CursorPage(list, nextCursor), nil
+}
+
+// applyFilter 将过滤条件转换为查询条件，列名来自 columns 映射而非用户输入
+func applyFilter(query *gorm.DB, column string, filter rest.Filter) *gorm.DB {
+	quoted := clause.Column{Name: column}
+	switch filter.Op {
+	case rest.OpEq:
+		return query.Where("? = ?", quoted, filter.Value)
+	case rest.OpNe:
+		return query.Where("? <> ?", quoted, filter.Value)
+	case rest.OpGt:
+		return query.Where("? > ?", quoted, filter.Value)
+	case rest.OpGte:
+		return query.Where("? >= ?", quoted, filter.Value)
+	case rest.OpLt:
+		return query.Where("? < ?", quoted, filter.Value)
+	case rest.OpLte:
+		return query.Where("? <= ?", quoted, filter.Value)
+	case rest.OpIn:
+		return query.Where("? IN ?", quoted, filter.Values)
+	case rest.OpNin:
+		return query.Where("? NOT IN ?", quoted, filter.Values)
+	case rest.OpLike:
+		escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(filter.Value.(string))
+		return query.Where("? LIKE ?", quoted, "%"+escaped+"%")
+	case rest.OpNull:
+		if filter.Value.(bool) {
+			return query.Where("? IS NULL", quoted)
+		}
+		return query.Where("? IS NOT NULL", quoted)
+	}
+	return query
+}
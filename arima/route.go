@@ -3,8 +3,10 @@ package main
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/akagiyui/go-together/rest"
+	"github.com/akagiyui/go-together/rest/respcache"
 
 	"github.com/akagiyui/go-together/arima/config"
 	"github.com/akagiyui/go-together/arima/middleware"
@@ -13,12 +15,20 @@ import (
 	"github.com/akagiyui/go-together/arima/service/user"
 )
 
-const comment = `🚀 Server starting on http://LISTEN`
+const comment = `🚀 Server starting on SCHEME://LISTEN`
+
+// responseCache 耗时接口的响应缓存
+var responseCache = respcache.New(nil)
 
 func registerRoute() {
 	cfg := config.GlobalConfig
-	registerV1Route(s.Group("/v1"))
-	println(strings.Replace(comment, "LISTEN", fmt.Sprintf("%s:%s", cfg.Host, cfg.Port), 1))
+	api := s.Versioning(rest.VersioningOptions{Strategy: rest.VersionByPath})
+	registerV1Route(api.Version("v1").RouteGroup)
+	scheme := "http"
+	if cfg.TLSCertFile != "" {
+		scheme = "https"
+	}
+	println(strings.NewReplacer("SCHEME", scheme, "LISTEN", fmt.Sprintf("%s:%s", cfg.Host, cfg.Port)).Replace(comment))
 }
 
 func registerV1Route(r *rest.RouteGroup) {
@@ -27,6 +37,9 @@ func registerV1Route(r *rest.RouteGroup) {
 	// 需要认证的路由组
 	requireAuthGroup := r.Group("", middleware.RequireAuth())
 	{
+		// 批量请求，子请求继承认证信息并各自经过完整的中间件
+		requireAuthGroup.Post("/batch", rest.Batch(rest.BatchOptions{MaxRequests: 20, Concurrency: 4}))
+
 		// 用户路由
 		userGroup := requireAuthGroup.Group("/users")
 		{
@@ -40,7 +53,7 @@ func registerV1Route(r *rest.RouteGroup) {
 		// 用户管理
 		userGroup := requireSuperuserGroup.Group("/users")
 		{
-			userGroup.Post("", rest.Service[user.CreateUserRequest]())
+			userGroup.Post("", middleware.IdempotencyMiddleware(), rest.Service[user.CreateUserRequest]())
 		}
 
 		// 音频路由
@@ -49,13 +62,19 @@ func registerV1Route(r *rest.RouteGroup) {
 			audioGroup.Get("", rest.Service[audio.ListAudioRequest]())
 			audioGroup.Get("/origin", rest.Service[audio.ListOriginAudioRequest]())
 			audioGroup.Get("/origin/{id}/url", rest.Service[audio.GetOriginAudioDownloadURLRequest]())
-			audioGroup.Post("/origin", rest.Service[audio.UploadOriginAudioRequest]())
+			audioGroup.Post("/origin", middleware.IdempotencyMiddleware(), rest.Service[audio.UploadOriginAudioRequest]())
 		}
 
 		// 系统路由
 		systemGroup := requireSuperuserGroup.Group("/system")
 		{
-			systemGroup.Get("", rest.Service[system.GetSystemInfoRequest]())
+			// 系统信息需要调用 ffmpeg，缓存一分钟，过期后十分钟内先返回旧数据再在后台刷新
+			systemGroup.Get("", responseCache.Middleware(respcache.Options{
+				Name:                 "system.info",
+				TTL:                  time.Minute,
+				StaleWhileRevalidate: 10 * time.Minute,
+				Shared:               true, // 位于超级用户认证之后，所有管理员看到的系统信息相同
+			}), rest.Service[system.GetSystemInfoRequest]())
 		}
 	}
 }
@@ -1,12 +1,25 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
 	"github.com/akagiyui/go-together/common/model"
 	"github.com/akagiyui/go-together/common/object"
+	"github.com/akagiyui/go-together/common/validation"
 	"github.com/akagiyui/go-together/rest"
+	"github.com/akagiyui/go-together/rest/health"
+	"github.com/akagiyui/go-together/rest/secure"
+	"gorm.io/gorm"
 
 	"github.com/akagiyui/go-together/arima/config"
 	"github.com/akagiyui/go-together/arima/middleware"
+	"github.com/akagiyui/go-together/arima/mock"
+	"github.com/akagiyui/go-together/arima/pkg/ffmpeg"
+	"github.com/akagiyui/go-together/arima/pkg/s3"
+	"github.com/akagiyui/go-together/arima/repo"
 )
 
 var s *rest.Server = rest.NewServer()
@@ -15,19 +28,42 @@ func init() {
 	cfg := config.GlobalConfig
 	s.Debug = cfg.Mode == config.ModeDev
 
+	// 注册可注入的依赖
+	rest.ProvideValue(s, cfg)
+	rest.Provide(s, func() *s3.Client { return s3.S3Client })
+	rest.Provide(s, func() *gorm.DB { return repo.DB })
+
+	// mock 模式，接口返回示例响应文件或根据响应类型生成的示例
+	if cfg.Mock {
+		s.EnableMock(rest.MockOptions{Fixtures: mock.Fixtures})
+	}
+
 	// 设置全局校验错误处理器
 	s.SetValidationErrorHandler(func(ctx *rest.Context, err error) {
-		ctx.SetResult(model.Error(model.ErrInputError, err.Error()))
+		resp := model.Error(model.ErrInputError, err.Error())
+		// 标签校验失败时附带每个字段的错误信息
+		var fieldErrs validation.Errors
+		if errors.As(err, &fieldErrs) {
+			resp.Data = fieldErrs.Fields()
+		}
+		ctx.SetResult(resp)
 	})
 
 	// 设置全局中间件
 	if object.HasText(cfg.AllowOrigin) {
 		s.Use(middleware.CorsMiddleware(cfg.AllowOrigin))
 	}
+	s.Use(secure.Middleware(secure.Options{}))
 	s.Use(middleware.TimeConsumeMiddleware())
 
-	// 统一封装响应体并设置HTTP状态码
-	s.Use(middleware.ResponseWrapperMiddleware())
+	// 统一封装响应体并设置HTTP状态码，在所有中间件之后执行
+	s.OnResponse(middleware.ResponseWrapperHook())
+	// 处理器 panic 时同样返回统一的响应体
+	s.OnError(func(ctx *rest.Context, err error) {
+		if ctx.StatusCode == http.StatusInternalServerError {
+			ctx.SetResult(model.InternalError())
+		}
+	})
 
 	// 设置 404 处理器
 	s.SetNotFound(func(ctx *rest.Context) {
@@ -38,7 +74,33 @@ func init() {
 	s.Get("/healthz", func(ctx *rest.Context) {
 		ctx.SetResult(model.Success(GetBuildInfo()))
 	})
+	registerHealthChecks(cfg)
 
 	// 注册业务路由
 	registerRoute()
 }
+
+// registerHealthChecks 注册存活和就绪检查，/readyz 检查数据库、S3 和 ffmpeg
+func registerHealthChecks(cfg config.Config) {
+	checker := health.New()
+	checker.Register("database", func(ctx context.Context) error {
+		sqlDB, err := repo.DB.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+	checker.Register("s3", func(ctx context.Context) error {
+		return s3.S3Client.HeadBucket(ctx)
+	}, health.CheckOptions{CacheTTL: 10 * time.Second})
+	// 调用外部命令的代价较高，缓存一分钟
+	ff := ffmpeg.NewFFmpeg(cfg.FFmpegExecutable, cfg.FFprobeExecutable)
+	checker.Register("ffmpeg", func(ctx context.Context) error {
+		if _, err := ff.FFmpegVersion(ctx); err != nil {
+			return err
+		}
+		_, err := ff.FFprobeVersion(ctx)
+		return err
+	}, health.CheckOptions{CacheTTL: time.Minute})
+	checker.Mount(&s.RouteGroup)
+}
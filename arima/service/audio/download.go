@@ -20,6 +20,11 @@ func (r GetOriginAudioDownloadURLRequest) Validate() error {
 	return validation.PositiveInt64(r.ID, "ID")
 }
 
+// Response 声明响应类型，mock 模式下的示例见 arima/mock/fixtures
+func (r GetOriginAudioDownloadURLRequest) Response() any {
+	return ""
+}
+
 // Do 处理获取原始音频下载URL请求
 func (r GetOriginAudioDownloadURLRequest) Do() (any, error) {
 	audio, err := repo.GetOriginAudioByID(r.ID)
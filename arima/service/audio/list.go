@@ -3,52 +3,61 @@ package audio
 
 import (
 	"github.com/akagiyui/go-together/common/model"
+	"github.com/akagiyui/go-together/rest"
 
 	"github.com/akagiyui/go-together/arima/repo"
 )
 
+// audioColumns 音频列表允许排序和过滤的字段
+var audioColumns = map[string]string{
+	"id":        "id",
+	"trackId":   "track_id",
+	"format":    "format",
+	"hasLyric":  "has_lyric",
+	"hasCover":  "has_cover",
+	"isDirty":   "is_dirty",
+	"createdAt": "created_at",
+}
+
 // ListAudioRequest 获取音频列表请求
 type ListAudioRequest struct {
-	PageIndex int `query:"page_index"`
-	PageSize  int `query:"page_size"`
+	Page rest.PageQuery `page:"sort=id,createdAt;default=id;filter=trackId:int,format,hasLyric:bool,hasCover:bool,isDirty:bool,createdAt:time"`
+}
+
+// Response 声明响应类型
+func (r ListAudioRequest) Response() any {
+	return model.PageData{List: []repo.Audio{}}
 }
 
 // Do 处理获取音频列表请求
 func (r ListAudioRequest) Do() (any, error) {
-	if r.PageIndex < 1 {
-		r.PageIndex = 1
-	}
-	if r.PageSize < 1 {
-		r.PageSize = 20
-	}
-
-	list, total, err := repo.GetAudioList(r.PageIndex, r.PageSize)
-	if err != nil {
-		return nil, err
-	}
+	return repo.FindPage[repo.Audio](repo.DB, r.Page, audioColumns)
+}
 
-	return model.Page(total, list), nil
+// originAudioColumns 原始音频列表允许排序和过滤的字段
+var originAudioColumns = map[string]string{
+	"id":         "id",
+	"fileName":   "file_name",
+	"format":     "format",
+	"title":      "title",
+	"artist":     "artist",
+	"album":      "album",
+	"isRejected": "is_rejected",
+	"source":     "source",
+	"createdAt":  "created_at",
 }
 
 // ListOriginAudioRequest 获取原始音频列表请求
 type ListOriginAudioRequest struct {
-	PageIndex int `query:"page_index"`
-	PageSize  int `query:"page_size"`
+	Page rest.PageQuery `page:"sort=id,fileName,title,artist,album,createdAt;default=-id;filter=fileName,format,title,artist,album,isRejected:bool,source,createdAt:time"`
+}
+
+// Response 声明响应类型
+func (r ListOriginAudioRequest) Response() any {
+	return model.PageData{List: []repo.OriginAudio{}}
 }
 
 // Do 处理获取原始音频列表请求
 func (r ListOriginAudioRequest) Do() (any, error) {
-	if r.PageIndex < 1 {
-		r.PageIndex = 1
-	}
-	if r.PageSize < 1 {
-		r.PageSize = 20
-	}
-
-	list, total, err := repo.GetOriginAudioList(r.PageIndex, r.PageSize)
-	if err != nil {
-		return nil, err
-	}
-
-	return model.Page(total, list), nil
+	return repo.FindPage[repo.OriginAudio](repo.DB, r.Page, originAudioColumns)
 }
@@ -25,6 +25,11 @@ type UploadOriginAudioRequest struct {
 	Source *string                 `form:"source"`
 }
 
+// Response 声明响应类型
+func (r UploadOriginAudioRequest) Response() any {
+	return []repo.OriginAudio{}
+}
+
 // Do 处理上传原始音频请求
 func (r UploadOriginAudioRequest) Do() (any, error) {
 	if len(r.Files) == 0 {
@@ -4,14 +4,19 @@ package system
 import (
 	"context"
 
+	"gorm.io/gorm"
+
 	"github.com/akagiyui/go-together/arima/config"
 	"github.com/akagiyui/go-together/arima/pkg/ffmpeg"
 	"github.com/akagiyui/go-together/arima/pkg/s3"
-	"github.com/akagiyui/go-together/arima/repo"
 )
 
 // GetSystemInfoRequest 获取系统信息请求
-type GetSystemInfoRequest struct{}
+type GetSystemInfoRequest struct {
+	Config config.Config `inject:""`
+	S3     *s3.Client    `inject:""`
+	DB     *gorm.DB      `inject:""`
+}
 
 // Info 系统信息响应
 type Info struct {
@@ -26,20 +31,25 @@ type Info struct {
 	DBHealth          bool   `json:"dbHealth"`
 }
 
+// Response 声明响应类型
+func (r GetSystemInfoRequest) Response() any {
+	return Info{}
+}
+
 // Do 处理获取系统信息请求
 func (r GetSystemInfoRequest) Do() (any, error) {
-	cfg := config.GlobalConfig
+	cfg := r.Config
 	ff := ffmpeg.NewFFmpeg(cfg.FFmpegExecutable, cfg.FFprobeExecutable)
 
 	ffmpegVersion, _ := ff.FFmpegVersion(context.Background())
 	ffprobeVersion, _ := ff.FFprobeVersion(context.Background())
 
 	// 检查 S3 健康状态
-	s3Health := s3.S3Client.IsHealthy(context.Background())
+	s3Health := r.S3.IsHealthy(context.Background())
 
 	// 检查数据库健康状态
 	dbHealth := false
-	if sqlDB, err := repo.DB.DB(); err == nil {
+	if sqlDB, err := r.DB.DB(); err == nil {
 		dbHealth = sqlDB.Ping() == nil
 	}
 
@@ -1,9 +1,6 @@
 package user
 
 import (
-	"errors"
-
-	"github.com/akagiyui/go-together/common/validation"
 	"github.com/google/uuid"
 
 	"github.com/akagiyui/go-together/arima/repo"
@@ -11,15 +8,12 @@ import (
 
 // CreateUserRequest 创建用户请求
 type CreateUserRequest struct {
-	Name string `json:"name"`
+	Name string `json:"name" label:"用户名" validate:"required,max=255"`
 }
 
-// Validate 校验创建用户请求参数
-func (r CreateUserRequest) Validate() error {
-	return errors.Join(
-		validation.Required(r.Name, "用户名"),
-		validation.MaxLength(r.Name, 255, "用户名"),
-	)
+// Response 声明响应类型
+func (r CreateUserRequest) Response() any {
+	return repo.User{}
 }
 
 // Do 执行创建用户业务逻辑
@@ -10,6 +10,11 @@ type GetUserMeRequest struct {
 	User repo.User `context:"user"`
 }
 
+// Response 声明响应类型
+func (r GetUserMeRequest) Response() any {
+	return repo.User{}
+}
+
 // Do 处理获取当前用户信息请求
 func (r GetUserMeRequest) Do() (any, error) {
 	return r.User, nil
@@ -56,6 +56,28 @@ func (c *Map[K, V]) GetOrSet(key K, factory func() V) V {
 	return value
 }
 
+// Delete 删除值
+func (c *Map[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.data, key)
+}
+
+// DeleteFunc 删除所有满足 fn 的键值对，返回删除的数量
+func (c *Map[K, V]) DeleteFunc(fn func(key K, value V) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	deleted := 0
+	for key, value := range c.data {
+		if fn(key, value) {
+			delete(c.data, key)
+			deleted++
+		}
+	}
+	return deleted
+}
+
 // Len 返回缓存大小
 func (c *Map[K, V]) Len() int {
 	c.mu.RLock()
@@ -20,6 +20,8 @@ var (
 	ErrUnauthorized BusinessCode = errors.New("unauthorized")
 	// ErrInternalError 服务器内部错误
 	ErrInternalError BusinessCode = errors.New("internal error")
+	// ErrConflict 请求冲突(如相同的幂等键仍在处理中)
+	ErrConflict BusinessCode = errors.New("conflict")
 )
 
 var businessCodeMap = map[BusinessCode]int{
@@ -28,6 +30,7 @@ var businessCodeMap = map[BusinessCode]int{
 	ErrNotFound:      2,
 	ErrUnauthorized:  3,
 	ErrInternalError: 4,
+	ErrConflict:      5,
 }
 
 var businessCodeReverseMap = map[int]BusinessCode{}
@@ -53,12 +56,31 @@ var statusMap = map[BusinessCode]int{
 	ErrNotFound:      http.StatusNotFound,
 	ErrUnauthorized:  http.StatusUnauthorized,
 	ErrInternalError: http.StatusInternalServerError,
+	ErrConflict:      http.StatusConflict,
 }
 
-// HTTPStatus 将业务错误码转换为 HTTP 状态码
+// HTTPStatus 将业务错误码转换为 HTTP 状态码，支持使用 fmt.Errorf("%w: ...", code) 包装的错误
 func HTTPStatus(code BusinessCode) int {
-	if status, ok := statusMap[code]; ok {
+	if status, ok := statusMap[CodeOf(code)]; ok {
 		return status
 	}
 	return http.StatusInternalServerError
 }
+
+// CodeOf 返回错误对应的业务状态码，err 可以是包装了业务状态码的错误，无法识别时返回 ErrInternalError
+//
+// 使用示例:
+//
+//	err := fmt.Errorf("%w: cursor is invalid", model.ErrInputError)
+//	model.CodeOf(err) // model.ErrInputError
+func CodeOf(err error) BusinessCode {
+	if _, ok := businessCodeMap[err]; ok {
+		return err
+	}
+	for code := range businessCodeMap {
+		if errors.Is(err, code) {
+			return code
+		}
+	}
+	return ErrInternalError
+}
@@ -10,7 +10,7 @@ type PageData struct {
 
 // Page 创建分页数据
 func Page(total int64, list any) PageData {
-	if reflect.ValueOf(list).IsNil() && total == 0 {
+	if isNil(list) && total == 0 {
 		list = []any{}
 	}
 	return PageData{
@@ -18,3 +18,33 @@ func Page(total int64, list any) PageData {
 		List:  list,
 	}
 }
+
+// CursorPageData 游标分页数据结构
+type CursorPageData struct {
+	List       any    `json:"list"`
+	NextCursor string `json:"nextCursor"` // 为空表示没有下一页
+}
+
+// CursorPage 创建游标分页数据
+func CursorPage(list any, nextCursor string) CursorPageData {
+	if isNil(list) {
+		list = []any{}
+	}
+	return CursorPageData{
+		List:       list,
+		NextCursor: nextCursor,
+	}
+}
+
+// isNil 判断列表是否为 nil，包括 nil 接口和值为 nil 的切片、map、指针
+func isNil(list any) bool {
+	if list == nil {
+		return true
+	}
+	value := reflect.ValueOf(list)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Ptr, reflect.Interface, reflect.Chan, reflect.Func:
+		return value.IsNil()
+	}
+	return false
+}
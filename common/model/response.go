@@ -24,7 +24,7 @@ func Success(datas ...any) GeneralResponse {
 	}
 }
 
-// Error 返回错误响应
+// Error 返回错误响应，code 可以是包装了业务状态码的错误，默认使用错误信息作为 Message
 func Error(code BusinessCode, messages ...string) GeneralResponse {
 	message := code.Error()
 	if len(messages) > 0 {
@@ -33,7 +33,7 @@ func Error(code BusinessCode, messages ...string) GeneralResponse {
 	return GeneralResponse{
 		Message: message,
 		Data:    nil,
-		Code:    businessCodeMap[code],
+		Code:    businessCodeMap[CodeOf(code)],
 	}
 }
 
@@ -0,0 +1,54 @@
+package validation
+
+import (
+	"strings"
+)
+
+// FieldError 单个字段的校验错误
+type FieldError struct {
+	Field string // 字段路径，如 "items[0].name"
+	Rule  string // 未通过的校验规则，如 "min"
+	Param string // 校验规则参数，如 "min=3" 中的 "3"
+	Err   error  // 校验函数返回的原始错误
+}
+
+// Error 返回原始错误信息
+func (e *FieldError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap 返回原始错误
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Errors 结构体校验错误集合，按字段声明顺序排列
+// 可通过 errors.As 从 ValidateStruct 的返回值中取出
+type Errors []*FieldError
+
+// Error 以换行符连接所有错误信息，与 errors.Join 的格式保持一致
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap 返回所有字段错误，便于 errors.Is / errors.As 逐个匹配
+func (e Errors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, fieldErr := range e {
+		errs[i] = fieldErr
+	}
+	return errs
+}
+
+// Fields 按字段路径分组的错误信息，适合直接作为响应体返回
+func (e Errors) Fields() map[string][]string {
+	fields := make(map[string][]string, len(e))
+	for _, fieldErr := range e {
+		fields[fieldErr.Field] = append(fields[fieldErr.Field], fieldErr.Error())
+	}
+	return fields
+}
@@ -1,9 +1,9 @@
 package validation
 
 import (
-	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -11,9 +11,12 @@ import (
 
 // 结构体字段信息缓存
 type fieldInfo struct {
-	Index int
-	Name  string
-	Rules []ruleInfo
+	Index     int
+	Name      string
+	Label     string // label 标签，错误信息中代替字段路径，为空时使用字段路径
+	Rules     []ruleInfo
+	ElemRules []ruleInfo // dive 之后的规则，作用于切片、数组或 map 的每个元素
+	Nested    bool       // 字段类型中含有需要校验的结构体，需要递归校验
 }
 
 type ruleInfo struct {
@@ -21,6 +24,9 @@ type ruleInfo struct {
 	Param string
 }
 
+// diveRule 之后的规则作用于容器的每个元素
+const diveRule = "dive"
+
 var (
 	structCache      = make(map[reflect.Type][]fieldInfo)
 	structCacheMutex sync.RWMutex
@@ -31,8 +37,8 @@ var (
 //
 // 支持的校验规则：
 //   - required: 必填
-//   - min=N: 最小值（数值）或最小长度（字符串）
-//   - max=N: 最大值（数值）或最大长度（字符串）
+//   - min=N: 最小值（数值）或最小长度（字符串、切片、map）
+//   - max=N: 最大值（数值）或最大长度（字符串、切片、map）
 //   - len=N: 精确长度（字符串）
 //   - email: 邮箱格式
 //   - url: URL 格式
@@ -41,6 +47,15 @@ var (
 //   - numeric: 只包含数字
 //   - oneof=val1 val2 val3: 值必须是指定值之一
 //   - regexp=pattern: 正则表达式匹配
+//   - dive: 之后的规则作用于切片、数组或 map 的每个元素，如 "max=10,dive,required"
+//
+// 嵌套的结构体以及结构体切片、数组、map 会被递归校验，无需额外标签。
+// 带有 inject 或 context 标签的字段是注入的依赖，不会被校验。
+// 指针字段为 nil 时只检查 required 规则，否则按指向的值校验。
+// 字段名优先使用 json 标签，其次是结构体字段名，嵌套字段使用 "address.city"、"items[0].name" 形式的路径。
+// 错误信息默认使用字段路径，声明了 label 标签时使用标签值，如 `json:"name" label:"用户名"`。
+//
+// 校验失败时返回 Errors，可通过 errors.As 获取每个字段的错误。
 //
 // 示例：
 //
@@ -66,34 +81,141 @@ func ValidateStruct(v interface{}) error {
 		return fmt.Errorf("ValidateStruct 只能用于结构体类型")
 	}
 
-	typ := val.Type()
+	// 收集所有错误
+	var errs Errors
+	validateStructValue(val, "", &errs)
 
-	// 获取或构建字段信息缓存
-	fields := getStructFields(typ)
+	if len(errs) > 0 {
+		return errs
+	}
 
-	// 收集所有错误
-	var errs []error
+	return nil
+}
+
+// HasRules 检查类型（或其嵌套的结构体）是否声明了 validate 标签
+// 可用于在注册阶段判断是否需要调用 ValidateStruct
+func HasRules(typ reflect.Type) bool {
+	return containsRules(typ, make(map[reflect.Type]bool))
+}
 
-	// 遍历所有字段进行校验
-	for _, field := range fields {
+// validateStructValue 校验结构体的每个字段，错误追加到 errs
+func validateStructValue(val reflect.Value, path string, errs *Errors) {
+	for _, field := range getStructFields(val.Type()) {
 		fieldValue := val.Field(field.Index)
-		fieldName := field.Name
+		fieldName := joinPath(path, field.Name)
+		label := fieldName
+		if field.Label != "" {
+			label = field.Label
+		}
 
 		// 对每个规则进行校验
 		for _, rule := range field.Rules {
-			err := validateField(fieldValue, rule.Name, rule.Param, fieldName)
-			if err != nil {
-				errs = append(errs, err)
+			if err := validateField(fieldValue, rule.Name, rule.Param, label); err != nil {
+				*errs = append(*errs, &FieldError{Field: fieldName, Rule: rule.Name, Param: rule.Param, Err: err})
 			}
 		}
+
+		// 对容器中的每个元素应用 dive 之后的规则
+		if len(field.ElemRules) > 0 {
+			eachElem(fieldValue, fieldName, func(elem reflect.Value, elemName string) {
+				for _, rule := range field.ElemRules {
+					if err := validateField(elem, rule.Name, rule.Param, elemName); err != nil {
+						*errs = append(*errs, &FieldError{Field: elemName, Rule: rule.Name, Param: rule.Param, Err: err})
+					}
+				}
+			})
+		}
+
+		if field.Nested {
+			validateNested(fieldValue, fieldName, errs)
+		}
 	}
+}
 
-	// 使用 errors.Join 合并所有错误
-	if len(errs) > 0 {
-		return errors.Join(errs...)
+// validateNested 递归校验嵌套的结构体、结构体指针以及它们的切片、数组、map
+func validateNested(val reflect.Value, path string, errs *Errors) {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return
+		}
+		val = val.Elem()
 	}
 
-	return nil
+	switch val.Kind() {
+	case reflect.Struct:
+		validateStructValue(val, path, errs)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		eachElem(val, path, func(elem reflect.Value, elemName string) {
+			validateNested(elem, elemName, errs)
+		})
+	}
+}
+
+// eachElem 遍历切片、数组或 map 的元素，map 按键的字符串形式排序以保证错误顺序稳定
+func eachElem(val reflect.Value, path string, fn func(elem reflect.Value, elemName string)) {
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return
+		}
+		val = val.Elem()
+	}
+
+	switch val.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			fn(val.Index(i), path+"["+strconv.Itoa(i)+"]")
+		}
+	case reflect.Map:
+		keys := val.MapKeys()
+		names := make([]string, len(keys))
+		for i, key := range keys {
+			names[i] = fmt.Sprint(key.Interface())
+		}
+		order := make([]int, len(keys))
+		for i := range order {
+			order[i] = i
+		}
+		sort.Slice(order, func(a, b int) bool { return names[order[a]] < names[order[b]] })
+		for _, i := range order {
+			fn(val.MapIndex(keys[i]), path+"["+names[i]+"]")
+		}
+	}
+}
+
+// joinPath 拼接嵌套字段路径
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+// containsRules 检查类型中是否含有 validate 标签，visiting 用于处理自引用类型
+func containsRules(typ reflect.Type, visiting map[reflect.Type]bool) bool {
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array || typ.Kind() == reflect.Map {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return false
+	}
+	if visiting[typ] {
+		return false
+	}
+	visiting[typ] = true
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() || isBoundDependency(field) {
+			continue
+		}
+		if tag := field.Tag.Get("validate"); tag != "" && tag != "-" {
+			return true
+		}
+		if containsRules(field.Type, visiting) {
+			return true
+		}
+	}
+	return false
 }
 
 // getStructFields 获取结构体字段信息（带缓存）
@@ -111,36 +233,39 @@ func getStructFields(typ reflect.Type) []fieldInfo {
 	for i := 0; i < typ.NumField(); i++ {
 		field := typ.Field(i)
 
-		// 跳过未导出的字段
-		if !field.IsExported() {
+		// 跳过未导出的字段以及注入的依赖
+		if !field.IsExported() || isBoundDependency(field) {
 			continue
 		}
 
 		// 获取 validate tag
 		validateTag := field.Tag.Get("validate")
-		if validateTag == "" || validateTag == "-" {
+		if validateTag == "-" {
 			continue
 		}
 
-		// 解析规则
+		// 解析规则，dive 之后的规则作用于元素
 		rules := parseRules(validateTag)
-		if len(rules) == 0 {
-			continue
+		var elemRules []ruleInfo
+		for j, rule := range rules {
+			if rule.Name == diveRule {
+				rules, elemRules = rules[:j], rules[j+1:]
+				break
+			}
 		}
 
-		// 获取字段名（优先使用 json tag）
-		fieldName := field.Name
-		if jsonTag := field.Tag.Get("json"); jsonTag != "" {
-			parts := strings.Split(jsonTag, ",")
-			if parts[0] != "" && parts[0] != "-" {
-				fieldName = parts[0]
-			}
+		nested := containsRules(field.Type, make(map[reflect.Type]bool))
+		if len(rules) == 0 && len(elemRules) == 0 && !nested {
+			continue
 		}
 
 		fields = append(fields, fieldInfo{
-			Index: i,
-			Name:  fieldName,
-			Rules: rules,
+			Index:     i,
+			Name:      fieldName(field),
+			Label:     field.Tag.Get("label"),
+			Rules:     rules,
+			ElemRules: elemRules,
+			Nested:    nested,
 		})
 	}
 
@@ -152,6 +277,26 @@ func getStructFields(typ reflect.Type) []fieldInfo {
 	return fields
 }
 
+// isBoundDependency 判断字段是否由 inject 或 context 标签绑定
+// 这类字段是服务端注入的依赖而非请求参数，不参与校验
+func isBoundDependency(field reflect.StructField) bool {
+	if _, ok := field.Tag.Lookup("inject"); ok {
+		return true
+	}
+	return field.Tag.Get("context") != ""
+}
+
+// fieldName 获取字段名（优先使用 json tag）
+func fieldName(field reflect.StructField) string {
+	if jsonTag := field.Tag.Get("json"); jsonTag != "" {
+		name, _, _ := strings.Cut(jsonTag, ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
 // parseRules 解析校验规则
 // 例如: "required,min=3,max=20,alphanum" -> [{required, ""}, {min, "3"}, {max, "20"}, {alphanum, ""}]
 func parseRules(tag string) []ruleInfo {
@@ -188,6 +333,14 @@ func validateField(field reflect.Value, ruleName string, param string, fieldName
 		return customValidator(field, param, fieldName)
 	}
 
+	// 指针字段：nil 只可能违反 required，否则按指向的值校验
+	if field.Kind() == reflect.Ptr && ruleName != "required" {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+
 	// 内置校验规则
 	switch ruleName {
 	case "required":
@@ -283,6 +436,10 @@ func validateMin(field reflect.Value, param string, fieldName string) error {
 		if field.Uint() < uint64(minVal) {
 			return fmt.Errorf("%s不能小于%d", fieldName, minVal)
 		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if field.Len() < minVal {
+			return fmt.Errorf("%s数量不能少于%d个", fieldName, minVal)
+		}
 	default:
 		return fmt.Errorf("min 规则不支持 %s 类型", field.Kind())
 	}
@@ -310,6 +467,10 @@ func validateMax(field reflect.Value, param string, fieldName string) error {
 		if field.Uint() > uint64(maxVal) {
 			return fmt.Errorf("%s不能大于%d", fieldName, maxVal)
 		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if field.Len() > maxVal {
+			return fmt.Errorf("%s数量不能超过%d个", fieldName, maxVal)
+		}
 	default:
 		return fmt.Errorf("max 规则不支持 %s 类型", field.Kind())
 	}
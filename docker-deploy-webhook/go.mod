@@ -1,5 +1,15 @@
 module github.com/akagiyui/go-together/docker-deploy-webhook
 
-go 1.24.5
+go 1.26.0
 
-require github.com/BurntSushi/toml v1.5.0
+require (
+	github.com/BurntSushi/toml v1.5.0
+	github.com/akagiyui/go-together/rest v0.0.0
+)
+
+require github.com/akagiyui/go-together/common v0.0.0 // indirect
+
+replace (
+	github.com/akagiyui/go-together/common => ../common
+	github.com/akagiyui/go-together/rest => ../rest
+)
@@ -3,6 +3,7 @@
 package main
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
@@ -14,8 +15,10 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
+	"github.com/akagiyui/go-together/rest/health"
 )
 
 // Config 存储应用配置
@@ -306,6 +309,15 @@ func healthHandler(w http.ResponseWriter, _ *http.Request) {
 	fmt.Fprint(w, "OK")
 }
 
+// newHealthChecker 创建健康检查，就绪检查确认可以执行 docker compose
+func newHealthChecker() *health.Checker {
+	checker := health.New()
+	checker.Register("docker", func(ctx context.Context) error {
+		return exec.CommandContext(ctx, "docker", "compose", "version").Run()
+	}, health.CheckOptions{CacheTTL: time.Minute})
+	return checker
+}
+
 func main() {
 	// 加载配置
 	config, err := loadConfig()
@@ -327,6 +339,9 @@ func main() {
 	// 设置路由
 	http.HandleFunc("/webhook", webhookHandler(config))
 	http.HandleFunc("/health", healthHandler)
+	checker := newHealthChecker()
+	http.Handle("/livez", checker.HTTPHandler(health.Liveness))
+	http.Handle("/readyz", checker.HTTPHandler(health.Readiness))
 
 	// 启动服务器
 	addr := ":" + config.Server.Port
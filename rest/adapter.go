@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"reflect"
 	"sync"
+
+	"github.com/akagiyui/go-together/common/validation"
 )
 
 // handlerNameRegistry 存储 HandlerFunc 指针到类型名称的映射
@@ -17,6 +19,19 @@ func registerHandlerName(f HandlerFunc, name string) {
 	handlerNameRegistry.Store(ptr, name)
 }
 
+// handlerDependencyRegistry 存储 HandlerFunc 指针到 inject 依赖类型的映射
+// 用于在路由注册到服务器时检查依赖是否都已提供
+var handlerDependencyRegistry = sync.Map{}
+
+// registerHandlerDependencies 注册 HandlerFunc 需要注入的依赖类型
+func registerHandlerDependencies(f HandlerFunc, dependencies []reflect.Type) {
+	if len(dependencies) == 0 {
+		return
+	}
+	ptr := reflect.ValueOf(f).Pointer()
+	handlerDependencyRegistry.Store(ptr, dependencies)
+}
+
 // getRegisteredHandlerName 获取已注册的 HandlerFunc 名称
 func getRegisteredHandlerName(f HandlerFunc) (string, bool) {
 	ptr := reflect.ValueOf(f).Pointer()
@@ -47,49 +62,64 @@ func Service[T any, PT interface {
 	// 获取类型的完整名称
 	typeName := t.PkgPath() + "." + t.Name()
 
+	// 是否声明了 validate 标签，只在注册时检查一次
+	hasRules := validation.HasRules(t)
+
+	// 分页参数的 page 标签格式错误时在注册时 panic，而不是在首次请求时
+	checkPageTags(t)
+
+	// 是否需要解析 JSON 请求体以及需要注入的依赖，只在注册时计算一次
+	plan := newBindingPlan(t)
+
 	handler := func(ctx *Context) {
 		// 创建新实例
 		handlerValue := reflect.New(t)
 		handlerPtr := handlerValue.Interface().(PT)
 
+		// 解析 JSON 请求体，需要在绑定其他参数之前，避免客户端通过请求体覆盖注入的依赖和上下文字段
+		if plan.jsonBody && ctx.BodyType == JSON && ctx.ContentLength > 0 {
+			if err := json.Unmarshal(ctx.FillBody(), handlerPtr); err != nil {
+				ctx.SetStatusCode(http.StatusBadRequest)
+				ctx.SetResult("Invalid JSON format: " + err.Error())
+				return
+			}
+		}
+
 		// 解析参数并注入字段
-		needParseJSONBody, err := parseParams(ctx, handlerPtr)
-		if err != nil {
+		if err := parseParams(ctx, handlerPtr); err != nil {
 			ctx.SetStatusCode(http.StatusBadRequest)
 			ctx.SetResult("Failed to parse parameters: " + err.Error())
 			return
 		}
 
-		// 解析 JSON 请求体
-		if needParseJSONBody && ctx.BodyType == JSON && ctx.ContentLength > 0 {
-			if err := json.Unmarshal(ctx.FillBody(), handlerPtr); err != nil {
-				ctx.SetStatusCode(http.StatusBadRequest)
-				ctx.SetResult("Invalid JSON format: " + err.Error())
-				return
-			}
+		// 参数绑定钩子
+		if !ctx.Server.runBindHooks(ctx, handlerPtr) {
+			return
 		}
 
 		// 执行校验
-		if validator, ok := any(handlerPtr).(Validator); ok {
-			if err := validator.Validate(); err != nil {
-				if ctx.Server.validationErrorHandler != nil {
-					ctx.Server.validationErrorHandler(ctx, err)
-				} else {
-					ctx.SetStatusCode(http.StatusBadRequest)
-					ctx.SetResult("Validation failed: " + err.Error())
-				}
-				return
-			}
+		if !validateRequest(ctx, handlerPtr, hasRules) {
+			return
+		}
+
+		// mock 模式下返回示例响应
+		if ctx.Server != nil && ctx.Server.mock != nil {
+			ctx.Server.mock.mockResponse(ctx, t, handlerPtr)
+			return
 		}
 
 		// 调用 Do 方法
 		result, err := handlerPtr.Do()
 		ctx.SetResult(result)
 		ctx.SetStatus(err)
+		if err != nil {
+			ctx.Server.runErrorHooks(ctx, err)
+		}
 	}
 
-	// 注册 handler 名称
+	// 注册 handler 名称和依赖
 	registerHandlerName(handler, typeName)
+	registerHandlerDependencies(handler, plan.dependencies)
 	return handler
 }
 
@@ -114,46 +144,75 @@ func Struct[T any, PT interface {
 	// 获取类型的完整名称
 	typeName := t.PkgPath() + "." + t.Name()
 
+	// 是否声明了 validate 标签，只在注册时检查一次
+	hasRules := validation.HasRules(t)
+
+	// 分页参数的 page 标签格式错误时在注册时 panic，而不是在首次请求时
+	checkPageTags(t)
+
+	// 是否需要解析 JSON 请求体以及需要注入的依赖，只在注册时计算一次
+	plan := newBindingPlan(t)
+
 	handler := func(ctx *Context) {
 		// 创建新实例
 		handlerValue := reflect.New(t)
 		handlerPtr := handlerValue.Interface().(PT)
 
+		// 解析 JSON 请求体，需要在绑定其他参数之前，避免客户端通过请求体覆盖注入的依赖和上下文字段
+		if plan.jsonBody && ctx.BodyType == JSON && ctx.ContentLength > 0 {
+			if err := json.Unmarshal(ctx.FillBody(), handlerPtr); err != nil {
+				ctx.SetStatusCode(http.StatusBadRequest)
+				ctx.SetResult("Invalid JSON format: " + err.Error())
+				return
+			}
+		}
+
 		// 解析参数并注入字段
-		needParseJSONBody, err := parseParams(ctx, handlerPtr)
-		if err != nil {
+		if err := parseParams(ctx, handlerPtr); err != nil {
 			ctx.SetStatusCode(http.StatusBadRequest)
 			ctx.SetResult("Failed to parse parameters: " + err.Error())
 			return
 		}
 
-		// 解析 JSON 请求体
-		if needParseJSONBody && ctx.BodyType == JSON && ctx.ContentLength > 0 {
-			if err := json.Unmarshal(ctx.FillBody(), handlerPtr); err != nil {
-				ctx.SetStatusCode(http.StatusBadRequest)
-				ctx.SetResult("Invalid JSON format: " + err.Error())
-				return
-			}
+		// 参数绑定钩子
+		if !ctx.Server.runBindHooks(ctx, handlerPtr) {
+			return
 		}
 
 		// 执行校验
-		if validator, ok := any(handlerPtr).(Validator); ok {
-			if err := validator.Validate(); err != nil {
-				if ctx.Server.validationErrorHandler != nil {
-					ctx.Server.validationErrorHandler(ctx, err)
-				} else {
-					ctx.SetStatusCode(http.StatusBadRequest)
-					ctx.SetResult("Validation failed: " + err.Error())
-				}
-				return
-			}
+		if !validateRequest(ctx, handlerPtr, hasRules) {
+			return
 		}
 
 		// 调用 Handle 方法
 		handlerPtr.Handle(ctx)
 	}
 
-	// 注册 handler 名称
+	// 注册 handler 名称和依赖
 	registerHandlerName(handler, typeName)
+	registerHandlerDependencies(handler, plan.dependencies)
 	return handler
 }
+
+// validateRequest 依次执行 validate 标签校验和 Validator 接口校验
+// 校验失败时交给全局校验错误处理器，未设置时返回 400，返回值表示是否通过
+func validateRequest(ctx *Context, handler any, hasRules bool) bool {
+	var err error
+	if hasRules {
+		err = validation.ValidateStruct(handler)
+	}
+	if validator, ok := handler.(Validator); ok && err == nil {
+		err = validator.Validate()
+	}
+	if err == nil {
+		return true
+	}
+
+	if ctx.Server.validationErrorHandler != nil {
+		ctx.Server.validationErrorHandler(ctx, err)
+	} else {
+		ctx.SetStatusCode(http.StatusBadRequest)
+		ctx.SetResult("Validation failed: " + err.Error())
+	}
+	return false
+}
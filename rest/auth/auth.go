@@ -0,0 +1,136 @@
+// Package auth 提供 rest 框架的认证中间件，支持 Bearer、API Key、HTTP Basic 和 JWT
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/akagiyui/go-together/rest"
+)
+
+var (
+	// ErrNoCredentials 请求中没有携带凭证
+	ErrNoCredentials = errors.New("auth: no credentials")
+	// ErrInvalidCredentials 凭证无效
+	ErrInvalidCredentials = errors.New("auth: invalid credentials")
+)
+
+// Authenticator 认证器，从请求中解析凭证并返回对应的主体（如用户）
+// 请求未携带凭证时应返回 ErrNoCredentials，凭证无效时返回其他错误
+type Authenticator interface {
+	Authenticate(ctx *rest.Context) (principal any, err error)
+}
+
+// AuthenticatorFunc 函数式认证器
+type AuthenticatorFunc func(ctx *rest.Context) (any, error)
+
+// Authenticate 实现 Authenticator 接口
+func (f AuthenticatorFunc) Authenticate(ctx *rest.Context) (any, error) {
+	return f(ctx)
+}
+
+// Challenger 可选接口，认证失败时用于生成 WWW-Authenticate 响应头
+type Challenger interface {
+	Challenge() string
+}
+
+// principalKey 在 Context.Memory 中存储认证主体的键
+type principalKey struct{}
+
+// Options 认证中间件配置
+type Options struct {
+	// Optional 为 true 时，未携带凭证的请求会继续执行，不设置主体
+	// 携带了无效凭证的请求仍会被拒绝
+	Optional bool
+	// ContextKey 额外将主体存入 Context.Memory 的键，便于通过 `context:"..."` 标签绑定
+	ContextKey string
+	// OnError 认证失败时的处理器，默认返回 401
+	OnError func(ctx *rest.Context, err error)
+}
+
+// Middleware 创建认证中间件，认证成功后主体会被存入上下文
+//
+// 使用示例:
+//
+//	server.Use(auth.Middleware(auth.APIKey(auth.BearerToken(), keys), auth.Options{}))
+func Middleware(authenticator Authenticator, options Options) rest.HandlerFunc {
+	onError := options.OnError
+	if onError == nil {
+		onError = func(ctx *rest.Context, _ error) {
+			if challenger, ok := authenticator.(Challenger); ok {
+				ctx.Response.Header("WWW-Authenticate", challenger.Challenge())
+			}
+			ctx.SetStatusCode(http.StatusUnauthorized)
+			ctx.SetResult("Unauthorized")
+		}
+	}
+
+	return func(ctx *rest.Context) {
+		principal, err := authenticator.Authenticate(ctx)
+		if err != nil {
+			if options.Optional && errors.Is(err, ErrNoCredentials) {
+				return
+			}
+			onError(ctx, err)
+			ctx.Abort()
+			return
+		}
+		SetPrincipal(ctx, principal)
+		if options.ContextKey != "" {
+			ctx.Set(options.ContextKey, principal)
+		}
+	}
+}
+
+// Require 要求请求已认证，未认证时返回 401
+// 通常与 Options.Optional 一起使用，在部分路由上强制认证
+func Require() rest.HandlerFunc {
+	return func(ctx *rest.Context) {
+		if _, exists := ctx.Get(principalKey{}); !exists {
+			ctx.SetStatusCode(http.StatusUnauthorized)
+			ctx.SetResult("Unauthorized")
+			ctx.Abort()
+		}
+	}
+}
+
+// SetPrincipal 将认证主体存入上下文
+func SetPrincipal(ctx *rest.Context, principal any) {
+	ctx.Set(principalKey{}, principal)
+}
+
+// Principal 从上下文中获取指定类型的认证主体
+// 未认证或类型不匹配时返回 (零值, false)
+func Principal[T any](ctx *rest.Context) (T, bool) {
+	var zero T
+	value, exists := ctx.Get(principalKey{})
+	if !exists {
+		return zero, false
+	}
+	principal, ok := value.(T)
+	return principal, ok
+}
+
+// Chain 依次尝试多个认证器，返回第一个找到凭证的认证器的结果
+func Chain(authenticators ...Authenticator) Authenticator {
+	return AuthenticatorFunc(func(ctx *rest.Context) (any, error) {
+		for _, authenticator := range authenticators {
+			principal, err := authenticator.Authenticate(ctx)
+			if errors.Is(err, ErrNoCredentials) {
+				continue
+			}
+			return principal, err
+		}
+		return nil, ErrNoCredentials
+	})
+}
+
+// Equal 以常数时间比较两个字符串，避免通过响应时间推测密钥
+// 先计算摘要再比较，因此也不会泄露长度信息
+func Equal(a, b string) bool {
+	ha := sha256.Sum256([]byte(a))
+	hb := sha256.Sum256([]byte(b))
+	return subtle.ConstantTimeCompare(ha[:], hb[:]) == 1
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/akagiyui/go-together/rest"
+)
+
+// Extractor 从请求中提取凭证字符串，未找到时返回 ("", false)
+type Extractor func(ctx *rest.Context) (string, bool)
+
+// FromHeader 从请求头中提取凭证
+// scheme 不为空时，要求请求头以 "<scheme> " 开头（不区分大小写），并去除该前缀
+func FromHeader(name, scheme string) Extractor {
+	return func(ctx *rest.Context) (string, bool) {
+		value := strings.TrimSpace(ctx.Request.Header.Get(name))
+		if scheme != "" {
+			if len(value) <= len(scheme) || !strings.EqualFold(value[:len(scheme)], scheme) || value[len(scheme)] != ' ' {
+				return "", false
+			}
+			value = strings.TrimSpace(value[len(scheme)+1:])
+		}
+		return value, value != ""
+	}
+}
+
+// FromQuery 从查询参数中提取凭证
+func FromQuery(name string) Extractor {
+	return func(ctx *rest.Context) (string, bool) {
+		value := ctx.Query.Get(name)
+		return value, value != ""
+	}
+}
+
+// FromCookie 从 Cookie 中提取凭证
+func FromCookie(name string) Extractor {
+	return func(ctx *rest.Context) (string, bool) {
+		value, ok := ctx.Cookie(name)
+		return value, ok && value != ""
+	}
+}
+
+// FirstOf 依次尝试多个提取器，返回第一个提取到的凭证
+func FirstOf(extractors ...Extractor) Extractor {
+	return func(ctx *rest.Context) (string, bool) {
+		for _, extractor := range extractors {
+			if value, ok := extractor(ctx); ok {
+				return value, true
+			}
+		}
+		return "", false
+	}
+}
+
+// BearerToken 从 Authorization: Bearer <token> 中提取凭证
+func BearerToken() Extractor {
+	return FromHeader("Authorization", "Bearer")
+}
@@ -0,0 +1,274 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// 支持的 JWT 签名算法
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+)
+
+var (
+	// ErrTokenMalformed JWT 格式错误
+	ErrTokenMalformed = errors.New("auth: malformed token")
+	// ErrTokenUnverifiable JWT 使用了不支持或未配置密钥的算法
+	ErrTokenUnverifiable = errors.New("auth: unverifiable token")
+	// ErrTokenSignatureInvalid JWT 签名无效
+	ErrTokenSignatureInvalid = errors.New("auth: token signature is invalid")
+	// ErrTokenExpired JWT 已过期
+	ErrTokenExpired = errors.New("auth: token is expired")
+	// ErrTokenNotValidYet JWT 尚未生效
+	ErrTokenNotValidYet = errors.New("auth: token is not valid yet")
+	// ErrTokenInvalidClaims JWT 的签发者或受众不匹配
+	ErrTokenInvalidClaims = errors.New("auth: token has invalid claims")
+)
+
+// Audience JWT aud 字段，兼容字符串和字符串数组两种格式
+type Audience []string
+
+// UnmarshalJSON 实现 json.Unmarshaler 接口
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*a = multiple
+	return nil
+}
+
+// NumericDate JWT 中的时间，自 1970-01-01T00:00:00Z 起的秒数
+// 解码时兼容带小数和指数的数值，小数部分会被舍去
+type NumericDate int64
+
+// NewNumericDate 将 time.Time 转换为 NumericDate
+func NewNumericDate(t time.Time) NumericDate {
+	return NumericDate(t.Unix())
+}
+
+// Time 转换为 time.Time
+func (d NumericDate) Time() time.Time {
+	return time.Unix(int64(d), 0)
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler 接口
+func (d *NumericDate) UnmarshalJSON(data []byte) error {
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return err
+	}
+	if seconds, err := number.Int64(); err == nil {
+		*d = NumericDate(seconds)
+		return nil
+	}
+	seconds, err := number.Float64()
+	if err != nil {
+		return err
+	}
+	if math.IsNaN(seconds) || math.IsInf(seconds, 0) || seconds >= math.MaxInt64 || seconds < math.MinInt64 {
+		return fmt.Errorf("auth: numeric date %s is out of range", number)
+	}
+	*d = NumericDate(seconds)
+	return nil
+}
+
+// RegisteredClaims JWT 标准声明，可嵌入自定义 claims 结构体中
+type RegisteredClaims struct {
+	Issuer    string      `json:"iss,omitempty"`
+	Subject   string      `json:"sub,omitempty"`
+	Audience  Audience    `json:"aud,omitempty"`
+	ExpiresAt NumericDate `json:"exp,omitempty"`
+	NotBefore NumericDate `json:"nbf,omitempty"`
+	IssuedAt  NumericDate `json:"iat,omitempty"`
+	ID        string      `json:"jti,omitempty"`
+}
+
+// JWTOptions JWT 校验配置
+// HMACKey 和 PublicKey 至少设置一个，令牌只能使用已配置密钥对应的算法，HMACKey 不能为空切片
+type JWTOptions struct {
+	Extractor Extractor      // 令牌提取器，默认为 BearerToken()
+	HMACKey   []byte         // HS256 密钥
+	PublicKey *rsa.PublicKey // RS256 公钥
+
+	Issuer   string        // 不为空时要求 iss 与之相等
+	Audience string        // 不为空时要求 aud 包含该值
+	Leeway   time.Duration // 校验 exp 和 nbf 时允许的时钟偏差
+
+	Now func() time.Time // 当前时间，默认为 time.Now
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+}
+
+// JWT 创建 JWT 认证器，载荷会被解码为 C 类型作为认证主体
+//
+// 使用示例:
+//
+//	type Claims struct {
+//	    auth.RegisteredClaims
+//	    Role string `json:"role"`
+//	}
+//
+//	server.Use(auth.Middleware(auth.JWT[Claims](auth.JWTOptions{HMACKey: secret}), auth.Options{}))
+//	// 在处理器中
+//	claims, ok := auth.Principal[Claims](ctx)
+func JWT[C any](options JWTOptions) Authenticator {
+	if options.HMACKey != nil && len(options.HMACKey) == 0 {
+		panic("auth.JWT: HMACKey must not be empty")
+	}
+	if options.HMACKey == nil && options.PublicKey == nil {
+		panic("auth.JWT: either HMACKey or PublicKey is required")
+	}
+	extractor := options.Extractor
+	if extractor == nil {
+		extractor = BearerToken()
+	}
+	return tokenAuthenticator{
+		extractor: extractor,
+		verify: func(token string) (any, error) {
+			var claims C
+			if err := VerifyJWT(token, options, &claims); err != nil {
+				return nil, err
+			}
+			return claims, nil
+		},
+		challenge: "Bearer",
+	}
+}
+
+// VerifyJWT 校验 JWT 的签名和标准声明，并将载荷解码到 claims
+func VerifyJWT(token string, options JWTOptions, claims any) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ErrTokenMalformed
+	}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return ErrTokenMalformed
+	}
+	var header jwtHeader
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return ErrTokenMalformed
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return ErrTokenMalformed
+	}
+
+	// 算法必须与已配置的密钥类型对应，防止算法混淆攻击
+	signingInput := parts[0] + "." + parts[1]
+	switch {
+	case header.Algorithm == HS256 && len(options.HMACKey) > 0:
+		mac := hmac.New(sha256.New, options.HMACKey)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return ErrTokenSignatureInvalid
+		}
+	case header.Algorithm == RS256 && options.PublicKey != nil:
+		digest := sha256.Sum256([]byte(signingInput))
+		if err := rsa.VerifyPKCS1v15(options.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+			return ErrTokenSignatureInvalid
+		}
+	default:
+		return ErrTokenUnverifiable
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ErrTokenMalformed
+	}
+	var registered RegisteredClaims
+	if err := json.Unmarshal(payload, &registered); err != nil {
+		return ErrTokenMalformed
+	}
+	if err := validateRegisteredClaims(registered, options); err != nil {
+		return err
+	}
+	if claims != nil {
+		if err := json.Unmarshal(payload, claims); err != nil {
+			return fmt.Errorf("%w: %s", ErrTokenMalformed, err.Error())
+		}
+	}
+	return nil
+}
+
+// validateRegisteredClaims 校验有效期、签发者和受众
+func validateRegisteredClaims(claims RegisteredClaims, options JWTOptions) error {
+	now := time.Now()
+	if options.Now != nil {
+		now = options.Now()
+	}
+	if claims.ExpiresAt != 0 && now.After(claims.ExpiresAt.Time().Add(options.Leeway)) {
+		return ErrTokenExpired
+	}
+	if claims.NotBefore != 0 && now.Before(claims.NotBefore.Time().Add(-options.Leeway)) {
+		return ErrTokenNotValidYet
+	}
+	if options.Issuer != "" && claims.Issuer != options.Issuer {
+		return ErrTokenInvalidClaims
+	}
+	if options.Audience != "" && !slices.Contains(claims.Audience, options.Audience) {
+		return ErrTokenInvalidClaims
+	}
+	return nil
+}
+
+// SignJWT 使用指定算法签发 JWT
+// HS256 的 key 为 []byte，RS256 的 key 为 *rsa.PrivateKey
+func SignJWT(claims any, algorithm string, key any) (string, error) {
+	headerBytes, err := json.Marshal(jwtHeader{Algorithm: algorithm, Type: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(headerBytes) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var signature []byte
+	switch algorithm {
+	case HS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return "", fmt.Errorf("auth: HS256 requires a []byte key, got %T", key)
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(signingInput))
+		signature = mac.Sum(nil)
+	case RS256:
+		privateKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return "", fmt.Errorf("auth: RS256 requires an *rsa.PrivateKey, got %T", key)
+		}
+		digest := sha256.Sum256([]byte(signingInput))
+		signature, err = rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest[:])
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("auth: unsupported algorithm %q", algorithm)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"fmt"
+
+	"github.com/akagiyui/go-together/rest"
+)
+
+// tokenAuthenticator 基于提取器和校验函数的通用令牌认证器
+type tokenAuthenticator struct {
+	extractor Extractor
+	verify    func(token string) (any, error)
+	challenge string
+}
+
+// Authenticate 实现 Authenticator 接口
+func (a tokenAuthenticator) Authenticate(ctx *rest.Context) (any, error) {
+	token, ok := a.extractor(ctx)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	return a.verify(token)
+}
+
+// Challenge 实现 Challenger 接口
+func (a tokenAuthenticator) Challenge() string {
+	return a.challenge
+}
+
+// Bearer 创建 Bearer 令牌认证器，verify 负责校验令牌并返回主体
+// extractor 为 nil 时使用 BearerToken()
+func Bearer(extractor Extractor, verify func(token string) (any, error)) Authenticator {
+	if extractor == nil {
+		extractor = BearerToken()
+	}
+	return tokenAuthenticator{
+		extractor: extractor,
+		verify:    verify,
+		challenge: "Bearer",
+	}
+}
+
+// APIKey 创建静态 API Key 认证器，keys 为 API Key 到主体的映射
+// 比较时会遍历所有 key 并使用常数时间比较，不会因命中位置不同而泄露信息
+func APIKey(extractor Extractor, keys map[string]any) Authenticator {
+	if extractor == nil {
+		extractor = BearerToken()
+	}
+	return tokenAuthenticator{
+		extractor: extractor,
+		verify: func(token string) (any, error) {
+			var principal any
+			found := false
+			for key, value := range keys {
+				if Equal(token, key) {
+					principal, found = value, true
+				}
+			}
+			if !found {
+				return nil, ErrInvalidCredentials
+			}
+			return principal, nil
+		},
+		challenge: "Bearer",
+	}
+}
+
+// basicAuthenticator HTTP Basic 认证器
+type basicAuthenticator struct {
+	realm  string
+	verify func(username, password string) (any, error)
+}
+
+// Basic 创建 HTTP Basic 认证器，verify 负责校验用户名密码并返回主体
+func Basic(realm string, verify func(username, password string) (any, error)) Authenticator {
+	return basicAuthenticator{
+		realm:  realm,
+		verify: verify,
+	}
+}
+
+// BasicUsers 创建基于静态用户表的 HTTP Basic 认证器，主体为用户名
+func BasicUsers(realm string, users map[string]string) Authenticator {
+	return Basic(realm, func(username, password string) (any, error) {
+		expected, ok := users[username]
+		if !ok {
+			// 用户不存在时仍执行一次比较，避免通过响应时间枚举用户名
+			Equal(password, password)
+			return nil, ErrInvalidCredentials
+		}
+		if !Equal(password, expected) {
+			return nil, ErrInvalidCredentials
+		}
+		return username, nil
+	})
+}
+
+// Authenticate 实现 Authenticator 接口
+func (a basicAuthenticator) Authenticate(ctx *rest.Context) (any, error) {
+	username, password, ok := ctx.OriginalRequest.BasicAuth()
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	return a.verify(username, password)
+}
+
+// Challenge 实现 Challenger 接口
+func (a basicAuthenticator) Challenge() string {
+	return fmt.Sprintf("Basic realm=%q, charset=\"UTF-8\"", a.realm)
+}
@@ -3,8 +3,10 @@ package rest
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"maps"
 	"mime"
 	"net/http"
 	"net/url"
@@ -19,6 +21,7 @@ type Request struct {
 	Method   string // GET, POST, PUT, DELETE, ...
 	Endpoint string // 不包含 query string
 	URI      string // 包含 query string
+	Pattern  string // 命中的路由路径，如 /users/{id}，未命中任何路由时为空
 
 	URL           url.URL
 	Host          string
@@ -51,6 +54,10 @@ type Context struct {
 
 	OriginalWriter  *http.ResponseWriter
 	OriginalRequest *http.Request
+	writer          http.ResponseWriter // 池化的上下文中 OriginalWriter 指向此字段，避免每个请求单独分配
+
+	requestContext  context.Context // 原始请求的 context，客户端断开时取消
+	timeoutDisabled bool            // 是否已通过 DisableTimeout 移除截止时间
 
 	memoryLock sync.RWMutex
 	Memory     map[any]any
@@ -59,8 +66,11 @@ type Context struct {
 
 	currentRunnerIndex int           // 私有索引：当前执行位置
 	runnerChain        []HandlerFunc // 当前请求的执行链
+	runnerNames        []string      // 执行链中每个处理器的名称，与 runnerChain 一一对应，可能为空
 
 	disableInternalResponse bool
+
+	afterResponse []func() // 响应写出后执行的函数，随上下文一起复用
 }
 
 // SetStatus 设置响应状态
@@ -83,6 +93,18 @@ func (c *Response) Header(key, value string) {
 	c.Headers.Add(key, value)
 }
 
+// HeadersChangedSince 返回相对于 before 新增或修改的响应头，before 为执行后续处理器之前 Headers 的副本
+// 用于需要保存并重放处理器响应头的中间件（如响应缓存、幂等），外层中间件设置的响应头不会包含在内
+func (c *Response) HeadersChangedSince(before http.Header) http.Header {
+	changed := make(http.Header)
+	for key, values := range c.Headers {
+		if !slices.Equal(before[key], values) {
+			changed[key] = slices.Clone(values)
+		}
+	}
+	return changed
+}
+
 // Get returns the value for the given key, ie: (value, true).
 // If the value does not exist it returns (nil, false)
 func (c *Context) Get(key any) (value any, exists bool) {
@@ -97,6 +119,9 @@ func (c *Context) Get(key any) (value any, exists bool) {
 func (c *Context) Set(key any, value any) {
 	c.memoryLock.Lock()
 	defer c.memoryLock.Unlock()
+	if c.Memory == nil {
+		c.Memory = make(map[any]any)
+	}
 	c.Memory[key] = value
 }
 
@@ -106,6 +131,19 @@ func (c *Context) Abort() {
 	c.currentRunnerIndex = len(c.runnerChain)
 }
 
+// AfterResponse 注册在响应写出后执行的函数，按注册的逆序执行
+// 处理器 panic 时也会在写出 500 响应后执行，可用于记录最终的状态码和耗时
+func (c *Context) AfterResponse(fn func()) {
+	c.afterResponse = append(c.afterResponse, fn)
+}
+
+// runAfterResponse 执行 AfterResponse 注册的函数
+func (c *Context) runAfterResponse() {
+	for i := len(c.afterResponse) - 1; i >= 0; i-- {
+		c.afterResponse[i]()
+	}
+}
+
 // IsAborted 检查是否已中止
 func (c *Context) IsAborted() bool {
 	return c.currentRunnerIndex >= len(c.runnerChain)
@@ -114,81 +152,174 @@ func (c *Context) IsAborted() bool {
 // Next executes the remaining handlers in the chain starting from the current index
 func (c *Context) Next() {
 	for c.currentRunnerIndex++; c.currentRunnerIndex < len(c.runnerChain); c.currentRunnerIndex++ {
+		if c.Server != nil && c.Server.tracer != nil {
+			c.runTraced(c.currentRunnerIndex)
+			continue
+		}
 		c.runnerChain[c.currentRunnerIndex](c)
 	}
 }
 
+// runTraced 在追踪器的 span 中执行指定位置的处理器
+func (c *Context) runTraced(index int) {
+	end := c.Server.tracer.StartHandler(c, c.runnerName(index))
+	defer end()
+	c.runnerChain[index](c)
+}
+
+// runnerName 获取执行链中指定位置处理器的名称
+func (c *Context) runnerName(index int) string {
+	if len(c.runnerNames) == len(c.runnerChain) {
+		return c.runnerNames[index]
+	}
+	return funcName(c.runnerChain[index])
+}
+
+// Context 返回请求的 context.Context，用于传递取消信号和追踪信息
+func (c *Context) Context() context.Context {
+	if c.OriginalRequest == nil {
+		return context.Background()
+	}
+	return c.OriginalRequest.Context()
+}
+
+// SetContext 替换请求的 context.Context，后续处理器通过 Context() 获取到的是新的值
+func (c *Context) SetContext(ctx context.Context) {
+	if c.OriginalRequest == nil {
+		return
+	}
+	c.OriginalRequest = c.OriginalRequest.WithContext(ctx)
+}
+
+// insertRunners 将 handlers 插入到当前执行位置之后，当前处理器返回后会依次执行
+// 执行链由同一路由的所有请求共享，因此这里必须复制而不能原地修改
+func (c *Context) insertRunners(handlers ...HandlerFunc) {
+	chain := make([]HandlerFunc, 0, len(c.runnerChain)+len(handlers))
+	chain = append(chain, c.runnerChain[:c.currentRunnerIndex+1]...)
+	chain = append(chain, handlers...)
+	chain = append(chain, c.runnerChain[c.currentRunnerIndex+1:]...)
+
+	// 保持名称与执行链对齐
+	if len(c.runnerNames) == len(c.runnerChain) {
+		names := make([]string, 0, len(chain))
+		names = append(names, c.runnerNames[:c.currentRunnerIndex+1]...)
+		for _, f := range handlers {
+			names = append(names, funcName(f))
+		}
+		names = append(names, c.runnerNames[c.currentRunnerIndex+1:]...)
+		c.runnerNames = names
+	}
+	c.runnerChain = chain
+}
+
+// contextPool 复用请求上下文，减少每个请求的内存分配
+var contextPool = sync.Pool{
+	New: func() any { return new(Context) },
+}
+
 // NewContext 创建一个新的请求上下文
+// 路径参数通过反射从 http.Request 中读取，框架内部注册的路由使用 acquireContext 从池中获取上下文
 func NewContext(r *http.Request, w *http.ResponseWriter, s *Server, runnerChain []HandlerFunc) *Context {
-	ctx := &Context{
-		Request: Request{
-			Method: r.Method,
-
-			Endpoint: r.URL.Path,
-			URI:      r.RequestURI,
-
-			URL:           *r.URL,
-			Host:          r.Host,
-			RemoteAddr:    r.RemoteAddr,
-			ContentLength: r.ContentLength,
-
-			Header:     r.Header,
-			PathParams: make(map[string]string),
-			Query:      r.URL.Query(),
-
-			BodyType: Nil,
-			Form:     nil, // Form 暂不处理
-			Body:     nil,
-		},
-		Response: Response{
-			Status:     nil,
-			StatusCode: http.StatusOK,
-			Result:     nil,
-			Headers:    make(http.Header),
-		},
-
-		OriginalWriter:  w,
-		OriginalRequest: r,
+	ctx := &Context{}
+	ctx.reset(r, w, s, runnerChain)
 
-		memoryLock: sync.RWMutex{},
-		Memory:     make(map[any]any),
+	// 解析路径参数
+	keys, values := parsePathParams(r)
+	for i, key := range keys {
+		ctx.setPathParam(key, values[i])
+	}
+	return ctx
+}
 
-		Server: s,
+// acquireContext 从池中获取上下文，pathKeys 为注册时从路由路径中解析出的参数名
+// 请求处理完毕后必须调用 releaseContext 归还
+func acquireContext(w http.ResponseWriter, r *http.Request, s *Server, runnerChain []HandlerFunc, pathKeys []string) *Context {
+	ctx := contextPool.Get().(*Context)
+	ctx.writer = w
+	ctx.reset(r, &ctx.writer, s, runnerChain)
+	for _, key := range pathKeys {
+		ctx.setPathParam(key, r.PathValue(key))
+	}
+	return ctx
+}
 
-		currentRunnerIndex: -1,
-		runnerChain:        runnerChain,
+// releaseContext 清空上下文并放回池中，保留已分配的 map 供下一个请求使用
+// 归还后不能再访问该上下文，需要在后台继续使用时应先调用 Copy
+func releaseContext(ctx *Context) {
+	headers, memory, pathParams, query := ctx.Response.Headers, ctx.Memory, ctx.PathParams, ctx.Query
+	clear(headers)
+	clear(memory)
+	clear(pathParams)
+	clear(query)
+	afterResponse := ctx.afterResponse
+	clear(afterResponse)
+
+	*ctx = Context{}
+	ctx.Response.Headers, ctx.Memory, ctx.PathParams, ctx.Query = headers, memory, pathParams, query
+	ctx.afterResponse = afterResponse[:0]
+	contextPool.Put(ctx)
+}
 
-		disableInternalResponse: false,
+// reset 根据请求初始化上下文，Memory 和 PathParams 在首次写入时才分配
+func (c *Context) reset(r *http.Request, w *http.ResponseWriter, s *Server, runnerChain []HandlerFunc) {
+	c.Method = r.Method
+	c.Endpoint = r.URL.Path
+	c.URI = r.RequestURI
+	c.URL = *r.URL
+	c.Host = r.Host
+	c.RemoteAddr = r.RemoteAddr
+	c.ContentLength = r.ContentLength
+	c.Request.Header = r.Header
+	if r.URL.RawQuery != "" {
+		c.Query = r.URL.Query()
+	} else if c.Query == nil {
+		c.Query = make(url.Values)
 	}
+	c.BodyType = Nil
 
-	// 解析请求体类型
-	contentType, _, err := mime.ParseMediaType(ctx.Request.Header.Get("Content-Type"))
+	c.StatusCode = http.StatusOK
+	if c.Response.Headers == nil {
+		c.Response.Headers = make(http.Header)
+	}
 
+	c.OriginalWriter = w
+	c.OriginalRequest = r
+	c.requestContext = r.Context()
+	c.Server = s
+	c.currentRunnerIndex = -1
+	c.runnerChain = runnerChain
+
+	// 解析请求体类型，没有 Content-Type 时无需解析
+	rawContentType := r.Header.Get("Content-Type")
+	if rawContentType == "" {
+		return
+	}
+	contentType, _, err := mime.ParseMediaType(rawContentType)
 	if err != nil {
-		if !slices.Contains([]string{http.MethodGet}, ctx.Method) && !strings.Contains(err.Error(), "no media type") {
-			fmt.Printf("Method: %s, Content-Type: %s\n", ctx.Method, contentType)
-			ctx.SetStatusCode(http.StatusBadRequest)
-			ctx.SetResult("Invalid Content-Type")
-			ctx.Abort()
-			return ctx
+		if !slices.Contains([]string{http.MethodGet}, c.Method) && !strings.Contains(err.Error(), "no media type") {
+			fmt.Printf("Method: %s, Content-Type: %s\n", c.Method, contentType)
+			c.SetStatusCode(http.StatusBadRequest)
+			c.SetResult("Invalid Content-Type")
+			c.Abort()
+			return
 		}
 	}
 	switch contentType {
 	case "application/x-www-form-urlencoded":
-		ctx.BodyType = EncodeURL
+		c.BodyType = EncodeURL
 	case "application/json":
-		ctx.BodyType = JSON
+		c.BodyType = JSON
 	case "multipart/form-data":
-		ctx.BodyType = FormData
+		c.BodyType = FormData
 	}
+}
 
-	// 解析路径参数
-	keys, values := parsePathParams(r)
-	for i, key := range keys {
-		ctx.PathParams[key] = values[i]
+// setPathParam 设置路径参数，首次写入时分配 map
+func (c *Context) setPathParam(key, value string) {
+	if c.PathParams == nil {
+		c.PathParams = make(map[string]string)
 	}
-
-	return ctx
+	c.PathParams[key] = value
 }
 
 func parsePathParams(r *http.Request) (keys []string, values []string) {
@@ -280,6 +411,7 @@ func (c *Context) FillBody() []byte {
 
 // Stream 流式响应
 func (c *Context) Stream(step func(w io.Writer) bool) bool {
+	c.DisableTimeout() // 流式响应不受路由超时限制
 	c.disableInternalResponse = true
 	c.Response.Headers.Add("Transfer-Encoding", "chunked")
 	c.writeHeaders()
@@ -313,6 +445,69 @@ func (c *Context) DisableInternalResponse() {
 	c.disableInternalResponse = true
 }
 
+// IsInternalResponseDisabled 检查是否已禁用内部响应处理，即处理器已自行写出响应
+func (c *Context) IsInternalResponseDisabled() bool {
+	return c.disableInternalResponse
+}
+
+// Copy 复制当前上下文，用于在后台 goroutine 中继续执行剩余的处理器
+// 副本的 Context() 不会随原请求结束而取消，响应不会写出到客户端
+func (c *Context) Copy() *Context {
+	c.memoryLock.RLock()
+	memory := make(map[any]any, len(c.Memory))
+	for key, value := range c.Memory {
+		memory[key] = value
+	}
+	c.memoryLock.RUnlock()
+
+	request := c.Request
+	request.Header = c.Request.Header.Clone()
+	request.Query = maps.Clone(c.Request.Query)
+	request.PathParams = maps.Clone(c.Request.PathParams)
+	response := c.Response
+	response.Headers = c.Response.Headers.Clone()
+
+	var w http.ResponseWriter = &discardWriter{header: make(http.Header)}
+	var r *http.Request
+	requestContext := context.Background()
+	if c.OriginalRequest != nil {
+		requestContext = context.WithoutCancel(c.requestContext)
+		r = c.OriginalRequest.Clone(context.WithoutCancel(c.Context()))
+	}
+
+	return &Context{
+		Request:  request,
+		Response: response,
+
+		OriginalWriter:  &w,
+		OriginalRequest: r,
+
+		requestContext:  requestContext,
+		timeoutDisabled: c.timeoutDisabled,
+
+		memoryLock: sync.RWMutex{},
+		Memory:     memory,
+
+		Server: c.Server,
+
+		currentRunnerIndex: c.currentRunnerIndex,
+		runnerChain:        c.runnerChain,
+		runnerNames:        c.runnerNames,
+
+		disableInternalResponse: c.disableInternalResponse,
+	}
+}
+
+// discardWriter 丢弃所有输出的 http.ResponseWriter，用于 Copy 得到的上下文
+type discardWriter struct {
+	header http.Header
+}
+
+func (w *discardWriter) Header() http.Header         { return w.header }
+func (w *discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardWriter) WriteHeader(int)             {}
+func (w *discardWriter) Flush()                      {}
+
 // NewEmptyContext 创建一个空的上下文实例
 func NewEmptyContext() Context {
 	return Context{
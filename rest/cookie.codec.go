@@ -0,0 +1,170 @@
+package rest
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxCookieSize 浏览器对单个 Cookie 的大小限制
+const maxCookieSize = 4096
+
+var (
+	// ErrCookieInvalid Cookie 格式错误或签名校验失败
+	ErrCookieInvalid = errors.New("rest: invalid cookie")
+	// ErrCookieExpired Cookie 签发时间超过了 MaxAge
+	ErrCookieExpired = errors.New("rest: cookie expired")
+	// ErrCookieTooLarge 编码后的 Cookie 超过浏览器的大小限制
+	ErrCookieTooLarge = errors.New("rest: cookie too large")
+)
+
+// CookieCodecOptions Cookie 编解码器配置
+type CookieCodecOptions struct {
+	// HashKey HMAC-SHA256 签名密钥，必填，建议至少 32 字节
+	HashKey []byte
+	// EncryptionKey AES-GCM 加密密钥，长度为 16、24 或 32 字节，为空时只签名不加密
+	EncryptionKey []byte
+	// MaxAge 签发后的有效期，超过后 Decode 返回 ErrCookieExpired，为 0 时不检查
+	MaxAge time.Duration
+}
+
+// CookieCodec 签名（可选加密）的 Cookie 编解码器，用于在 Cookie 中保存会话等结构化数据
+// 值经过 JSON 序列化后写入，签名同时覆盖 Cookie 名称和签发时间，防止篡改和替换
+type CookieCodec struct {
+	hashKey []byte
+	aead    cipher.AEAD
+	maxAge  time.Duration
+}
+
+// NewCookieCodec 创建 Cookie 编解码器
+func NewCookieCodec(options CookieCodecOptions) (*CookieCodec, error) {
+	if len(options.HashKey) == 0 {
+		return nil, errors.New("rest: cookie codec requires a hash key")
+	}
+	codec := &CookieCodec{
+		hashKey: options.HashKey,
+		maxAge:  options.MaxAge,
+	}
+	if len(options.EncryptionKey) > 0 {
+		block, err := aes.NewCipher(options.EncryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("rest: invalid cookie encryption key: %w", err)
+		}
+		codec.aead, err = cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return codec, nil
+}
+
+// Encode 将值编码为 Cookie 值
+// 格式为 base64url(签发时间|载荷|签名)，启用加密时载荷为 nonce 与密文
+func (c *CookieCodec) Encode(name string, value any) (string, error) {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	if c.aead != nil {
+		nonce := make([]byte, c.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", err
+		}
+		payload = c.aead.Seal(nonce, nonce, payload, []byte(name))
+	}
+
+	data := strconv.FormatInt(time.Now().Unix(), 10) + "|" + base64.RawURLEncoding.EncodeToString(payload)
+	signature := base64.RawURLEncoding.EncodeToString(c.sign(name, data))
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(data + "|" + signature))
+	if len(name)+len(encoded)+1 > maxCookieSize {
+		return "", ErrCookieTooLarge
+	}
+	return encoded, nil
+}
+
+// Decode 校验 Cookie 值的签名和有效期，并解码到 dst
+func (c *CookieCodec) Decode(name, encoded string, dst any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return ErrCookieInvalid
+	}
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 3 {
+		return ErrCookieInvalid
+	}
+	data := parts[0] + "|" + parts[1]
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, c.sign(name, data)) {
+		return ErrCookieInvalid
+	}
+
+	issuedAt, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return ErrCookieInvalid
+	}
+	if c.maxAge > 0 && time.Since(time.Unix(issuedAt, 0)) > c.maxAge {
+		return ErrCookieExpired
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ErrCookieInvalid
+	}
+	if c.aead != nil {
+		nonceSize := c.aead.NonceSize()
+		if len(payload) < nonceSize {
+			return ErrCookieInvalid
+		}
+		payload, err = c.aead.Open(nil, payload[:nonceSize], payload[nonceSize:], []byte(name))
+		if err != nil {
+			return ErrCookieInvalid
+		}
+	}
+	return json.Unmarshal(payload, dst)
+}
+
+// Set 编码值并通过 Set-Cookie 响应头写入，未指定 MaxAge 时使用编解码器的有效期
+func (c *CookieCodec) Set(ctx *Context, name string, value any, options ...CookieOptions) error {
+	encoded, err := c.Encode(name, value)
+	if err != nil {
+		return err
+	}
+	var option CookieOptions
+	if len(options) > 0 {
+		option = options[0]
+	}
+	if option.MaxAge == 0 {
+		option.MaxAge = c.maxAge
+	}
+	ctx.SetCookie(name, encoded, option)
+	return nil
+}
+
+// Get 读取请求中的 Cookie 并解码到 dst，Cookie 不存在时返回 http.ErrNoCookie
+func (c *CookieCodec) Get(ctx *Context, name string, dst any) error {
+	encoded, ok := ctx.Cookie(name)
+	if !ok {
+		return http.ErrNoCookie
+	}
+	return c.Decode(name, encoded, dst)
+}
+
+// sign 计算 Cookie 名称与数据的 HMAC 签名
+func (c *CookieCodec) sign(name, data string) []byte {
+	mac := hmac.New(sha256.New, c.hashKey)
+	mac.Write([]byte(name))
+	mac.Write([]byte{'|'})
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
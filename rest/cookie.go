@@ -0,0 +1,74 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+)
+
+// CookieOptions 设置 Cookie 时的可选参数
+// 零值即为安全的默认配置：Path 为 "/"，启用 HttpOnly 和 Secure，SameSite 为 Lax
+type CookieOptions struct {
+	Path     string        // 默认为 "/"
+	Domain   string        // 默认为空，仅当前域名可见
+	MaxAge   time.Duration // 大于 0 时设置 Max-Age，为 0 时是会话 Cookie
+	SameSite http.SameSite // 默认为 http.SameSiteLaxMode
+
+	AllowScript   bool // 允许前端脚本读取，即不设置 HttpOnly
+	AllowInsecure bool // 允许通过 HTTP 明文传输，即不设置 Secure，仅用于本地调试
+}
+
+// newCookie 按照选项创建 Cookie，并填充默认值
+func newCookie(name, value string, options []CookieOptions) *http.Cookie {
+	var option CookieOptions
+	if len(options) > 0 {
+		option = options[0]
+	}
+	if option.Path == "" {
+		option.Path = "/"
+	}
+	if option.SameSite == 0 {
+		option.SameSite = http.SameSiteLaxMode
+	}
+
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     option.Path,
+		Domain:   option.Domain,
+		SameSite: option.SameSite,
+		HttpOnly: !option.AllowScript,
+		Secure:   !option.AllowInsecure,
+	}
+	if option.MaxAge > 0 {
+		cookie.MaxAge = int(option.MaxAge / time.Second)
+		cookie.Expires = time.Now().Add(option.MaxAge).UTC()
+	}
+	return cookie
+}
+
+// SetCookie 添加 Set-Cookie 响应头，未传入选项时使用安全的默认配置
+// 名称或值不合法时不会写入，与 http.SetCookie 的行为一致
+func (c *Response) SetCookie(name, value string, options ...CookieOptions) {
+	if line := newCookie(name, value, options).String(); line != "" {
+		c.Header("Set-Cookie", line)
+	}
+}
+
+// DeleteCookie 通知客户端删除 Cookie，Path 和 Domain 需要与设置时一致
+func (c *Response) DeleteCookie(name string, options ...CookieOptions) {
+	cookie := newCookie(name, "", options)
+	cookie.MaxAge = -1
+	cookie.Expires = time.Unix(0, 0).UTC()
+	if line := cookie.String(); line != "" {
+		c.Header("Set-Cookie", line)
+	}
+}
+
+// Cookie 获取请求中指定名称的 Cookie 值
+func (c *Request) Cookie(name string) (string, bool) {
+	cookie, err := (&http.Request{Header: c.Header}).Cookie(name)
+	if err != nil {
+		return "", false
+	}
+	return cookie.Value, true
+}
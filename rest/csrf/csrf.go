@@ -0,0 +1,286 @@
+// Package csrf 提供防止跨站请求伪造的中间件，适用于使用 Cookie 认证的路由
+//
+// 每个客户端持有一个随机密钥，由 Storage 保存：默认保存在 Cookie 中（双重提交 Cookie 模式），
+// 也可以保存在服务端会话中（同步令牌模式）。页面或前端通过 Token 获取令牌，
+// 在不安全的请求（POST、PUT、PATCH、DELETE 等）中通过请求头或表单字段提交，中间件校验令牌与密钥是否匹配。
+// 此外还会检查 Origin 和 Referer，拒绝来自其他站点的请求。
+//
+// 使用 Authorization 请求头认证的 API 不会被浏览器自动携带凭证，不需要 CSRF 保护。
+package csrf
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/akagiyui/go-together/rest"
+)
+
+const (
+	// DefaultHeader 默认的令牌请求头
+	DefaultHeader = "X-CSRF-Token"
+	// DefaultFormField 默认的令牌表单字段
+	DefaultFormField = "csrf_token"
+	// DefaultCookieName 默认保存密钥的 Cookie 名称
+	DefaultCookieName = "csrf_secret"
+)
+
+// secretLength 密钥的字节数
+const secretLength = 32
+
+var (
+	// ErrTokenMissing 请求中没有携带令牌
+	ErrTokenMissing = errors.New("csrf: token missing")
+	// ErrTokenInvalid 令牌与密钥不匹配，或客户端没有密钥
+	ErrTokenInvalid = errors.New("csrf: token invalid")
+	// ErrOriginNotAllowed Origin 或 Referer 不是当前站点，也不在信任列表中
+	ErrOriginNotAllowed = errors.New("csrf: origin not allowed")
+)
+
+// Options CSRF 中间件配置
+type Options struct {
+	// Storage 保存密钥的位置，默认为 CookieStorage(DefaultCookieName, rest.CookieOptions{})
+	Storage Storage
+	// Header 提交令牌的请求头，默认为 DefaultHeader
+	Header string
+	// FormField 提交令牌的表单字段，默认为 DefaultFormField，仅在请求头中没有令牌时读取
+	FormField string
+	// TrustedOrigins 允许跨站提交的来源，如 "https://admin.example.com"，当前站点总是被允许
+	TrustedOrigins []string
+	// Skip 返回 true 时跳过校验，如使用 Authorization 请求头认证的请求
+	Skip func(ctx *rest.Context) bool
+	// OnError 校验失败时的处理器，默认返回 403
+	OnError func(ctx *rest.Context, err error)
+}
+
+// stateKey 当前请求的 CSRF 状态在 ctx.Memory 中的键
+type stateKey struct{}
+
+// state 当前请求的密钥，首次需要时才从 Storage 读取或生成
+type state struct {
+	storage Storage
+	secret  []byte
+	loaded  bool
+}
+
+// Middleware 创建 CSRF 中间件
+// 安全的请求方法（GET、HEAD、OPTIONS、TRACE）不做校验，其他请求需要同时通过来源检查和令牌校验
+//
+// 使用示例:
+//
+//	pages := server.Group("/account")
+//	pages.Use(csrf.Middleware(csrf.Options{}))
+//	pages.Get("/profile", func(ctx *rest.Context) {
+//	    // 在表单中加入 <input type="hidden" name="csrf_token" value="...">
+//	    ctx.SetResult(renderProfile(csrf.Token(ctx)))
+//	})
+//	pages.Post("/profile", updateProfile)
+func Middleware(options Options) rest.HandlerFunc {
+	storage := options.Storage
+	if storage == nil {
+		storage = CookieStorage(DefaultCookieName, rest.CookieOptions{})
+	}
+	header := options.Header
+	if header == "" {
+		header = DefaultHeader
+	}
+	formField := options.FormField
+	if formField == "" {
+		formField = DefaultFormField
+	}
+	trusted := make(map[string]bool, len(options.TrustedOrigins))
+	for _, origin := range options.TrustedOrigins {
+		trusted[strings.ToLower(strings.TrimSuffix(origin, "/"))] = true
+	}
+	onError := options.OnError
+	if onError == nil {
+		onError = func(ctx *rest.Context, _ error) {
+			ctx.SetStatusCode(http.StatusForbidden)
+			ctx.SetResult("Forbidden")
+		}
+	}
+
+	return func(ctx *rest.Context) {
+		s := &state{storage: storage}
+		ctx.Set(stateKey{}, s)
+
+		if isSafeMethod(ctx.Method) || (options.Skip != nil && options.Skip(ctx)) {
+			return
+		}
+
+		err := checkOrigin(ctx, trusted)
+		if err == nil {
+			err = checkToken(ctx, s, header, formField)
+		}
+		if err != nil {
+			onError(ctx, err)
+			ctx.Abort()
+		}
+	}
+}
+
+// Token 返回当前请求的令牌，客户端还没有密钥时生成并保存
+// 每次调用返回不同的值（密钥经过随机掩码），但都可以通过校验，用于防止 BREACH 等压缩侧信道攻击
+// 未使用 Middleware 或保存密钥失败时返回空字符串
+func Token(ctx *rest.Context) string {
+	s, ok := getState(ctx)
+	if !ok {
+		return ""
+	}
+	secret, err := s.load(ctx)
+	if err != nil {
+		return ""
+	}
+	if secret == nil {
+		if secret, err = s.generate(ctx); err != nil {
+			return ""
+		}
+	}
+	return mask(secret)
+}
+
+// Rotate 生成新的密钥，之前签发的令牌全部失效，应在登录、切换用户后调用以防止会话固定攻击
+func Rotate(ctx *rest.Context) error {
+	s, ok := getState(ctx)
+	if !ok {
+		return errors.New("csrf: middleware is not installed")
+	}
+	_, err := s.generate(ctx)
+	return err
+}
+
+// getState 获取中间件设置的状态
+func getState(ctx *rest.Context) (*state, bool) {
+	value, ok := ctx.Get(stateKey{})
+	if !ok {
+		return nil, false
+	}
+	return value.(*state), true
+}
+
+// load 读取密钥，长度不正确的密钥视为不存在
+func (s *state) load(ctx *rest.Context) ([]byte, error) {
+	if s.loaded {
+		return s.secret, nil
+	}
+	secret, err := s.storage.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(secret) != secretLength {
+		secret = nil
+	}
+	s.secret, s.loaded = secret, true
+	return secret, nil
+}
+
+// generate 生成并保存新的密钥
+func (s *state) generate(ctx *rest.Context) ([]byte, error) {
+	secret := make([]byte, secretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	if err := s.storage.Save(ctx, secret); err != nil {
+		return nil, err
+	}
+	s.secret, s.loaded = secret, true
+	return secret, nil
+}
+
+// checkToken 从请求头或表单中读取令牌，并与密钥比较
+func checkToken(ctx *rest.Context, s *state, header, formField string) error {
+	token := ctx.Request.Header.Get(header)
+	if token == "" && ctx.OriginalRequest != nil && (ctx.BodyType == rest.EncodeURL || ctx.BodyType == rest.FormData) {
+		// 与参数绑定使用相同的解析方式，解析结果会被缓存，不影响后续绑定 form 标签
+		if ctx.BodyType == rest.FormData {
+			ctx.OriginalRequest.ParseMultipartForm(32 << 20)
+		}
+		token = ctx.OriginalRequest.PostFormValue(formField)
+	}
+	if token == "" {
+		return ErrTokenMissing
+	}
+
+	secret, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+	if secret == nil || !unmaskEqual(token, secret) {
+		return ErrTokenInvalid
+	}
+	return nil
+}
+
+// checkOrigin 检查请求来源，优先使用 Sec-Fetch-Site，其次是 Origin，最后是 Referer
+// 都没有时视为非浏览器请求，只校验令牌；只比较主机名和端口，以兼容在反向代理处终止 TLS 的部署
+func checkOrigin(ctx *rest.Context, trusted map[string]bool) error {
+	header := ctx.Request.Header
+	origin := header.Get("Origin")
+	switch header.Get("Sec-Fetch-Site") {
+	case "same-origin", "none":
+		return nil
+	case "":
+	default:
+		// 其他来源（包括同站的其他子域名）的请求只允许来自信任列表
+		if trusted[strings.ToLower(origin)] {
+			return nil
+		}
+		return ErrOriginNotAllowed
+	}
+
+	if origin == "" {
+		referer := header.Get("Referer")
+		if referer == "" {
+			return nil
+		}
+		origin = referer
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		// 包括 Origin 为 "null" 的情况，如沙箱 iframe 和 data: URL
+		return ErrOriginNotAllowed
+	}
+	if strings.EqualFold(u.Host, ctx.Request.Host) || trusted[strings.ToLower(u.Scheme+"://"+u.Host)] {
+		return nil
+	}
+	return ErrOriginNotAllowed
+}
+
+// mask 使用一次性随机掩码编码密钥，格式为 base64url(掩码|掩码 XOR 密钥)
+func mask(secret []byte) string {
+	token := make([]byte, 2*len(secret))
+	pad := token[:len(secret)]
+	if _, err := rand.Read(pad); err != nil {
+		panic(err)
+	}
+	for i, b := range secret {
+		token[len(secret)+i] = pad[i] ^ b
+	}
+	return base64.RawURLEncoding.EncodeToString(token)
+}
+
+// unmaskEqual 还原令牌中的密钥并以常量时间与 secret 比较
+func unmaskEqual(token string, secret []byte) bool {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(raw) != 2*len(secret) {
+		return false
+	}
+	unmasked := make([]byte, len(secret))
+	for i := range unmasked {
+		unmasked[i] = raw[i] ^ raw[len(secret)+i]
+	}
+	return subtle.ConstantTimeCompare(unmasked, secret) == 1
+}
+
+// isSafeMethod 判断请求方法是否不会修改服务器状态
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
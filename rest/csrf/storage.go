@@ -0,0 +1,63 @@
+package csrf
+
+import (
+	"encoding/base64"
+
+	"github.com/akagiyui/go-together/rest"
+)
+
+// Storage 保存每个客户端的密钥
+// 保存在 Cookie 中即为双重提交 Cookie 模式；保存在服务端会话中即为同步令牌模式，密钥不会离开服务端
+type Storage interface {
+	// Load 读取当前客户端的密钥，不存在时返回 nil 和 nil
+	Load(ctx *rest.Context) ([]byte, error)
+	// Save 保存当前客户端的密钥
+	Save(ctx *rest.Context, secret []byte) error
+}
+
+// StorageFunc 函数式 Storage，便于接入已有的会话存储
+type StorageFunc struct {
+	LoadFunc func(ctx *rest.Context) ([]byte, error)
+	SaveFunc func(ctx *rest.Context, secret []byte) error
+}
+
+// Load 实现 Storage 接口
+func (s StorageFunc) Load(ctx *rest.Context) ([]byte, error) {
+	return s.LoadFunc(ctx)
+}
+
+// Save 实现 Storage 接口
+func (s StorageFunc) Save(ctx *rest.Context, secret []byte) error {
+	return s.SaveFunc(ctx, secret)
+}
+
+// cookieStorage 将密钥保存在 Cookie 中
+type cookieStorage struct {
+	name    string
+	options rest.CookieOptions
+}
+
+// CookieStorage 将密钥保存在指定名称的 Cookie 中，options 的零值即为安全的默认配置（HttpOnly、Secure、SameSite=Lax）
+// 前端脚本不需要读取该 Cookie，令牌应通过 Token 获取
+func CookieStorage(name string, options rest.CookieOptions) Storage {
+	return cookieStorage{name: name, options: options}
+}
+
+// Load 实现 Storage 接口，Cookie 格式错误时视为不存在
+func (s cookieStorage) Load(ctx *rest.Context) ([]byte, error) {
+	value, ok := ctx.Cookie(s.name)
+	if !ok {
+		return nil, nil
+	}
+	secret, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, nil
+	}
+	return secret, nil
+}
+
+// Save 实现 Storage 接口
+func (s cookieStorage) Save(ctx *rest.Context, secret []byte) error {
+	ctx.SetCookie(s.name, base64.RawURLEncoding.EncodeToString(secret), s.options)
+	return nil
+}
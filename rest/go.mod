@@ -1,3 +1,3 @@
 module github.com/akagiyui/go-together/rest
 
-go 1.22
+go 1.24
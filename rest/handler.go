@@ -32,7 +32,7 @@ type structInfo struct {
 type fieldInfo struct {
 	index     int
 	name      string
-	tagType   string // "query", "path", "header", "json", "form", "context"
+	tagType   string // "query", "path", "header", "cookie", "json", "form", "context", "inject"
 	tagValue  string
 	fieldType reflect.Type
 	isPtr     bool
@@ -56,12 +56,16 @@ func getStructInfo(t reflect.Type) *structInfo {
 				tagType, tagValue = "path", tag
 			} else if tag := field.Tag.Get("header"); tag != "" {
 				tagType, tagValue = "header", tag
+			} else if tag := field.Tag.Get("cookie"); tag != "" {
+				tagType, tagValue = "cookie", tag
 			} else if tag := field.Tag.Get("json"); tag != "" {
 				tagType, tagValue = "json", tag
 			} else if tag := field.Tag.Get("form"); tag != "" {
 				tagType, tagValue = "form", tag
 			} else if tag := field.Tag.Get("context"); tag != "" {
 				tagType, tagValue = "context", tag
+			} else if tag, ok := field.Tag.Lookup("inject"); ok {
+				tagType, tagValue = "inject", tag
 			}
 
 			if tagType != "" {
@@ -80,6 +84,32 @@ func getStructInfo(t reflect.Type) *structInfo {
 	})
 }
 
+// FieldBinding 结构体字段的参数绑定信息
+type FieldBinding struct {
+	Index  int          // 字段下标
+	Name   string       // 字段名
+	Source string       // 参数来源："query", "path", "header", "cookie", "json", "form", "context", "inject"
+	Key    string       // 标签值，如 `query:"page"` 中的 page
+	Type   reflect.Type // 字段类型
+}
+
+// StructFields 获取结构体带有绑定标签的字段，结果与参数绑定共用同一份缓存
+// 嵌套的结构体字段不会展开，t 必须是结构体类型
+func StructFields(t reflect.Type) []FieldBinding {
+	info := getStructInfo(t)
+	bindings := make([]FieldBinding, len(info.fields))
+	for i, field := range info.fields {
+		bindings[i] = FieldBinding{
+			Index:  field.index,
+			Name:   field.name,
+			Source: field.tagType,
+			Key:    field.tagValue,
+			Type:   field.fieldType,
+		}
+	}
+	return bindings
+}
+
 // funcName 获取 HandlerFunc 的函数名称
 // 优先查询 handlerNameRegistry，如果没有注册则使用 runtime.FuncForPC 获取
 func funcName(f HandlerFunc) string {
@@ -92,7 +122,8 @@ func funcName(f HandlerFunc) string {
 }
 
 // parseParams 解析query参数和path参数和header参数到结构体字段
-func parseParams(ctx *Context, handlerInterface interface{}) (needParseJSONBody bool, err error) {
+// 应在解析 JSON 请求体之后调用，非 json 标签的字段总是以请求参数、上下文和依赖为准，不会被请求体覆盖
+func parseParams(ctx *Context, handlerInterface interface{}) error {
 	handlerValue := reflect.ValueOf(handlerInterface)
 	if handlerValue.Kind() == reflect.Ptr {
 		handlerValue = handlerValue.Elem()
@@ -101,16 +132,16 @@ func parseParams(ctx *Context, handlerInterface interface{}) (needParseJSONBody
 }
 
 // 优化后的 parseStructFields
-func parseStructFields(structValue reflect.Value, ctx *Context) (needParseJSONBody bool, err error) {
+func parseStructFields(structValue reflect.Value, ctx *Context) (err error) {
 	if structValue.Kind() == reflect.Ptr {
 		if structValue.IsNil() {
-			return false, nil
+			return nil
 		}
 		structValue = structValue.Elem()
 	}
 
 	if structValue.Kind() != reflect.Struct {
-		return false, nil
+		return nil
 	}
 
 	structType := structValue.Type()
@@ -127,6 +158,10 @@ func parseStructFields(structValue reflect.Value, ctx *Context) (needParseJSONBo
 		if !fieldValue.CanSet() {
 			continue
 		}
+		if fieldInfo.tagType != "json" {
+			// 清除 JSON 请求体按字段名写入的值
+			fieldValue.SetZero()
+		}
 
 		switch fieldInfo.tagType {
 		case "query":
@@ -147,17 +182,24 @@ func parseStructFields(structValue reflect.Value, ctx *Context) (needParseJSONBo
 					return
 				}
 			}
+		case "cookie":
+			if cookieValue, ok := ctx.Cookie(fieldInfo.tagValue); ok {
+				if err = setFieldValue(fieldValue, cookieValue); err != nil {
+					return
+				}
+			}
 		case "context":
 			if contextValue, exists := ctx.Get(fieldInfo.tagValue); exists {
 				if err = setAnyValue(fieldValue, contextValue); err != nil {
 					return
 				}
 			}
-		case "json":
-			if ctx.BodyType == JSON {
-				// 交给 json.Unmarshal 处理
-				needParseJSONBody = true
+		case "inject":
+			if err = resolveDependency(ctx, fieldValue, fieldInfo.fieldType); err != nil {
+				return
 			}
+		case "json":
+			// 已由 json.Unmarshal 处理
 		case "form":
 			switch ctx.BodyType {
 			case EncodeURL:
@@ -166,7 +208,7 @@ func parseStructFields(structValue reflect.Value, ctx *Context) (needParseJSONBo
 				ctx.OriginalRequest.ParseForm()
 				form := ctx.OriginalRequest.PostForm
 				if form == nil {
-					return false, nil
+					return nil
 				}
 				if formValue, ok := form[fieldInfo.tagValue]; ok && len(formValue) > 0 {
 					if err = setFieldValue(fieldValue, formValue...); err != nil {
@@ -178,7 +220,7 @@ func parseStructFields(structValue reflect.Value, ctx *Context) (needParseJSONBo
 				ctx.OriginalRequest.ParseMultipartForm(32 << 20) // 32MB
 				form := ctx.OriginalRequest.MultipartForm
 				if form == nil {
-					return false, nil
+					return nil
 				}
 
 				// 处理普通表单字段
@@ -207,13 +249,8 @@ func parseStructFields(structValue reflect.Value, ctx *Context) (needParseJSONBo
 		fieldValue := structValue.Field(i)
 		fieldType := field.Type
 
-		// 检查是否有任何 tag
-		hasTag := slices.ContainsFunc([]string{"query", "path", "header", "json", "form", "context"}, func(tag string) bool {
-			return field.Tag.Get(tag) != ""
-		})
-
 		// 如果有标签，跳过嵌套结构体处理
-		if hasTag {
+		if hasBindingTag(field) {
 			continue
 		}
 
@@ -224,14 +261,95 @@ func parseStructFields(structValue reflect.Value, ctx *Context) (needParseJSONBo
 			fieldType = fieldType.Elem()
 		}
 
+		// 字段自行解析查询参数
+		if binder, ok := queryBinderOf(fieldValue); ok {
+			if err = binder.BindQuery(queryValues, field.Tag); err != nil {
+				return
+			}
+			continue
+		}
+
 		if fieldType.Kind() == reflect.Struct {
-			childNeedParseJSONBody, childErr := parseStructFields(fieldValue, ctx)
-			if childErr != nil {
-				return needParseJSONBody, childErr
+			if err = parseStructFields(fieldValue, ctx); err != nil {
+				return
 			}
-			needParseJSONBody = needParseJSONBody || childNeedParseJSONBody
 		}
 	}
 
 	return
 }
+
+// bindingPlan 注册时从结构体类型得到的参数绑定信息
+type bindingPlan struct {
+	jsonBody     bool           // 是否有 json 标签的字段，需要解析 JSON 请求体
+	dependencies []reflect.Type // inject 标签字段的类型
+}
+
+// newBindingPlan 按参数绑定的规则遍历结构体（包括未带标签的嵌套结构体），收集绑定信息
+func newBindingPlan(t reflect.Type) bindingPlan {
+	var plan bindingPlan
+	plan.collect(t, make(map[reflect.Type]bool))
+	return plan
+}
+
+// collect 收集结构体的绑定信息，visited 用于避免自引用的结构体无限递归
+func (p *bindingPlan) collect(t reflect.Type, visited map[reflect.Type]bool) {
+	if visited[t] {
+		return
+	}
+	visited[t] = true
+
+	for _, field := range getStructInfo(t).fields {
+		switch field.tagType {
+		case "json":
+			p.jsonBody = true
+		case "inject":
+			if !slices.Contains(p.dependencies, field.fieldType) {
+				p.dependencies = append(p.dependencies, field.fieldType)
+			}
+		}
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if hasBindingTag(field) {
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if reflect.PointerTo(fieldType).Implements(queryBinderType) {
+			continue
+		}
+		if fieldType.Kind() == reflect.Struct {
+			p.collect(fieldType, visited)
+		}
+	}
+}
+
+// hasBindingTag 检查字段是否带有任何绑定标签
+func hasBindingTag(field reflect.StructField) bool {
+	hasTag := slices.ContainsFunc([]string{"query", "path", "header", "cookie", "json", "form", "context"}, func(tag string) bool {
+		return field.Tag.Get(tag) != ""
+	})
+	if _, ok := field.Tag.Lookup("inject"); ok {
+		hasTag = true
+	}
+	return hasTag
+}
+
+// queryBinderOf 检查字段是否实现了 QueryBinder 接口
+func queryBinderOf(fieldValue reflect.Value) (QueryBinder, bool) {
+	if !fieldValue.CanInterface() {
+		return nil, false
+	}
+	if fieldValue.Kind() != reflect.Ptr {
+		if !fieldValue.CanAddr() {
+			return nil, false
+		}
+		fieldValue = fieldValue.Addr()
+	}
+	binder, ok := fieldValue.Interface().(QueryBinder)
+	return binder, ok
+}
@@ -0,0 +1,252 @@
+// Package health 提供存活（/livez）和就绪（/readyz）检查
+//
+// 存活检查表示进程本身是否正常，失败时应重启进程，因此只应包含不依赖外部服务的检查；
+// 就绪检查表示是否可以接收流量，包括所有检查，失败时负载均衡应暂时摘除该实例。
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/akagiyui/go-together/rest"
+)
+
+// DefaultTimeout 单次检查的默认超时时间
+const DefaultTimeout = 5 * time.Second
+
+// Status 检查状态
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// Probe 探针类型
+type Probe int
+
+const (
+	// Liveness 存活探针，只执行 CheckOptions.Liveness 为 true 的检查
+	Liveness Probe = iota
+	// Readiness 就绪探针，执行所有检查
+	Readiness
+)
+
+// CheckFunc 检查函数，返回 nil 表示健康，应在 ctx 取消后尽快返回
+type CheckFunc func(ctx context.Context) error
+
+// CheckOptions 检查配置
+type CheckOptions struct {
+	// Timeout 单次检查的超时时间，默认为 DefaultTimeout，超时视为失败
+	Timeout time.Duration
+	// CacheTTL 检查结果的缓存时间，为 0 时每次请求都执行检查，适用于代价较高的检查（如调用外部命令）
+	CacheTTL time.Duration
+	// Liveness 为 true 时同时参与存活检查，默认只参与就绪检查
+	Liveness bool
+}
+
+// CheckResult 单项检查的结果
+type CheckResult struct {
+	Status     Status    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	DurationMS float64   `json:"duration_ms"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// Report 汇总的检查结果，任意一项失败时整体为 StatusDown
+// Checks 只在详细模式下返回
+type Report struct {
+	Status Status                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// Checker 检查注册表，并发安全
+type Checker struct {
+	mu     sync.RWMutex
+	checks []*check
+}
+
+// check 已注册的检查及其缓存的结果
+type check struct {
+	name    string
+	fn      CheckFunc
+	options CheckOptions
+
+	mu   sync.Mutex // 同时只执行一次，并发的请求等待同一个结果
+	last CheckResult
+}
+
+// New 创建检查注册表
+func New() *Checker {
+	return &Checker{}
+}
+
+// Register 注册检查，名称重复时 panic
+//
+// 使用示例:
+//
+//	checker.Register("database", func(ctx context.Context) error {
+//	    return db.PingContext(ctx)
+//	})
+//	checker.Register("ffmpeg", checkFFmpeg, health.CheckOptions{CacheTTL: time.Minute})
+func (c *Checker) Register(name string, fn CheckFunc, options ...CheckOptions) {
+	var opts CheckOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if slices.ContainsFunc(c.checks, func(ch *check) bool { return ch.name == name }) {
+		panic(fmt.Sprintf("health: check %q is already registered", name))
+	}
+	c.checks = append(c.checks, &check{name: name, fn: fn, options: opts})
+}
+
+// Check 并发执行探针包含的检查并汇总结果，Report.Checks 总是包含每一项的结果
+func (c *Checker) Check(ctx context.Context, probe Probe) Report {
+	c.mu.RLock()
+	checks := make([]*check, 0, len(c.checks))
+	for _, ch := range c.checks {
+		if probe == Readiness || ch.options.Liveness {
+			checks = append(checks, ch)
+		}
+	}
+	c.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, ch := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = ch.run(ctx)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Checks: make(map[string]CheckResult, len(checks))}
+	for i, ch := range checks {
+		report.Checks[ch.name] = results[i]
+		if results[i].Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+// Handler 返回探针的处理器，健康时返回 200，否则返回 503
+// 请求携带 verbose 查询参数（如 /readyz?verbose）时返回每一项检查的结果
+func (c *Checker) Handler(probe Probe) rest.HandlerFunc {
+	return func(ctx *rest.Context) {
+		report, statusCode := c.respond(ctx.Context(), probe, ctx.Request.Query)
+		ctx.SetStatusCode(statusCode)
+		ctx.Response.Header("Cache-Control", "no-store")
+		ctx.SetResult(report)
+	}
+}
+
+// HTTPHandler 返回探针的 http.Handler，用于没有使用 rest 的服务，行为与 Handler 一致
+func (c *Checker) HTTPHandler(probe Probe) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report, statusCode := c.respond(r.Context(), probe, r.URL.Query())
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(report)
+	})
+}
+
+// Mount 在路由组下注册 GET /livez 和 GET /readyz
+//
+// 使用示例:
+//
+//	checker := health.New()
+//	checker.Register("database", pingDatabase)
+//	checker.Mount(&server.RouteGroup)
+func (c *Checker) Mount(g *rest.RouteGroup) {
+	g.Get("/livez", c.Handler(Liveness))
+	g.Get("/readyz", c.Handler(Readiness))
+}
+
+// run 执行检查，结果在 CacheTTL 内直接复用
+func (ch *check) run(ctx context.Context) CheckResult {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	if ch.options.CacheTTL > 0 && !ch.last.CheckedAt.IsZero() && time.Since(ch.last.CheckedAt) < ch.options.CacheTTL {
+		return ch.last
+	}
+
+	start := time.Now()
+	err := ch.execute(ctx)
+	result := CheckResult{
+		Status:     StatusUp,
+		DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+		CheckedAt:  start,
+	}
+	if err != nil {
+		result.Status, result.Error = StatusDown, err.Error()
+	}
+	// 请求被取消时的结果不代表依赖的真实状态，不缓存
+	if ch.options.CacheTTL > 0 && ctx.Err() == nil {
+		ch.last = result
+	}
+	return result
+}
+
+// execute 在超时时间内执行检查函数，检查函数不响应取消时不再等待，panic 视为失败
+func (ch *check) execute(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, ch.options.Timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- fmt.Errorf("panic: %v", recovered)
+			}
+		}()
+		done <- ch.fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("timed out after %s", ch.options.Timeout)
+		}
+		return ctx.Err()
+	}
+}
+
+// respond 执行检查并返回响应体和状态码，非详细模式下只返回整体状态
+func (c *Checker) respond(ctx context.Context, probe Probe, query url.Values) (Report, int) {
+	report := c.Check(ctx, probe)
+	if !isVerbose(query) {
+		report.Checks = nil
+	}
+	if report.Status != StatusUp {
+		return report, http.StatusServiceUnavailable
+	}
+	return report, http.StatusOK
+}
+
+// isVerbose 判断是否请求详细结果，verbose 参数为空或除 false、0 以外的值时为 true
+func isVerbose(query url.Values) bool {
+	if !query.Has("verbose") {
+		return false
+	}
+	value := query.Get("verbose")
+	return value != "false" && value != "0"
+}
@@ -0,0 +1,200 @@
+// Package idempotency 提供基于 Idempotency-Key 请求头的幂等中间件
+//
+// 携带相同键的重复请求不会再次执行处理器，而是直接返回首次请求的响应；
+// 首次请求仍在处理时，重复请求返回 409；相同的键用于不同的请求（方法、路径或请求体不同）时返回 422。
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/akagiyui/go-together/rest"
+)
+
+const (
+	// DefaultHeader 默认的幂等键请求头
+	DefaultHeader = "Idempotency-Key"
+	// ReplayedHeader 重放的响应会携带该响应头
+	ReplayedHeader = "Idempotent-Replayed"
+	// MaxKeyLength 幂等键的最大长度
+	MaxKeyLength = 255
+)
+
+// Options 幂等中间件配置
+type Options struct {
+	// Store 记录存储，为 nil 时使用 NewMemoryStore
+	Store Store
+	// Header 幂等键请求头，默认为 DefaultHeader
+	Header string
+	// TTL 响应记录的保存时间，默认 24 小时
+	TTL time.Duration
+	// LockTimeout 首次请求处理期间占用键的最长时间，超过后视为处理失败，默认 5 分钟
+	LockTimeout time.Duration
+	// Methods 需要处理的请求方法，默认为 POST 和 PATCH
+	Methods []string
+	// Required 为 true 时，未携带幂等键的请求返回 400
+	Required bool
+	// Scope 返回键的作用域，如当前用户 ID，避免不同用户的键互相冲突
+	Scope func(ctx *rest.Context) string
+	// OnConflict 首次请求仍在处理时的处理器，默认返回 409
+	OnConflict func(ctx *rest.Context)
+	// OnMismatch 相同的键已用于方法、路径或请求体不同的请求时的处理器，默认返回 422
+	OnMismatch func(ctx *rest.Context)
+	// ShouldStore 判断响应是否需要保存，默认保存状态码小于 500 的响应
+	// 不保存的响应会释放键，允许客户端使用相同的键重试
+	ShouldStore func(ctx *rest.Context) bool
+}
+
+// Middleware 创建幂等中间件
+//
+// 默认只保存状态码小于 500 的响应；处理器 panic、返回 5xx 或使用流式响应时会释放键，允许客户端重试。
+// 重放时会恢复首次请求的状态码、ctx.Status、处理器添加的响应头和 ctx.Result，
+// 外层中间件（如统一响应封装）会像处理普通请求一样再次处理它们。
+//
+// 使用示例:
+//
+//	userGroup.Post("", idempotency.Middleware(idempotency.Options{}), rest.Service[user.CreateUserRequest]())
+func Middleware(options Options) rest.HandlerFunc {
+	store := options.Store
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	header := options.Header
+	if header == "" {
+		header = DefaultHeader
+	}
+	ttl := options.TTL
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	lockTimeout := options.LockTimeout
+	if lockTimeout <= 0 {
+		lockTimeout = 5 * time.Minute
+	}
+	methods := options.Methods
+	if len(methods) == 0 {
+		methods = []string{http.MethodPost, http.MethodPatch}
+	}
+	shouldStore := options.ShouldStore
+	if shouldStore == nil {
+		shouldStore = func(ctx *rest.Context) bool {
+			return ctx.StatusCode < http.StatusInternalServerError
+		}
+	}
+	onConflict := options.OnConflict
+	if onConflict == nil {
+		onConflict = func(ctx *rest.Context) {
+			ctx.SetStatusCode(http.StatusConflict)
+			ctx.SetResult("Conflict: a request with the same " + header + " is in progress")
+		}
+	}
+	onMismatch := options.OnMismatch
+	if onMismatch == nil {
+		onMismatch = func(ctx *rest.Context) {
+			ctx.SetStatusCode(http.StatusUnprocessableEntity)
+			ctx.SetResult("Unprocessable Entity: " + header + " was used with a different request")
+		}
+	}
+
+	return func(ctx *rest.Context) {
+		if !slices.Contains(methods, ctx.Method) {
+			return
+		}
+		idempotencyKey := ctx.Request.Header.Get(header)
+		if idempotencyKey == "" {
+			if options.Required {
+				abort(ctx, http.StatusBadRequest, "Bad Request: missing "+header+" header")
+			}
+			return
+		}
+		if len(idempotencyKey) > MaxKeyLength {
+			abort(ctx, http.StatusBadRequest, "Bad Request: "+header+" is too long")
+			return
+		}
+
+		// 键只按作用域隔离，同一个键用于不同的请求时通过请求指纹识别
+		key := idempotencyKey
+		if options.Scope != nil {
+			key = options.Scope(ctx) + " " + idempotencyKey
+		}
+		fingerprint := requestFingerprint(ctx)
+
+		// 请求超时或客户端断开后仍需要完成存储操作
+		storeCtx := context.WithoutCancel(ctx.Context())
+		record, err := store.Begin(storeCtx, key, lockTimeout)
+		switch {
+		case errors.Is(err, ErrInFlight):
+			onConflict(ctx)
+			ctx.Abort()
+			return
+		case err != nil:
+			abort(ctx, http.StatusServiceUnavailable, "Service Unavailable: "+err.Error())
+			return
+		case record != nil && record.Fingerprint != fingerprint:
+			onMismatch(ctx)
+			ctx.Abort()
+			return
+		case record != nil:
+			replay(ctx, record)
+			ctx.Abort()
+			return
+		}
+
+		before := ctx.Headers.Clone()
+		completed := false
+		defer func() {
+			if !completed {
+				// 处理器 panic，释放键后继续向上抛出
+				_ = store.Release(storeCtx, key)
+			}
+		}()
+		ctx.Next()
+		completed = true
+
+		// 处理器自行写出的响应（如流式响应）和文件响应无法重放
+		if _, isFile := ctx.Result.(*rest.FileResult); isFile || ctx.IsInternalResponseDisabled() || !shouldStore(ctx) {
+			_ = store.Release(storeCtx, key)
+			return
+		}
+		record = &Record{
+			Fingerprint: fingerprint,
+			StatusCode:  ctx.StatusCode,
+			Status:      ctx.Status,
+			Headers:     ctx.HeadersChangedSince(before),
+			Result:      ctx.Result,
+		}
+		if err := store.Complete(storeCtx, key, record, ttl); err != nil {
+			_ = store.Release(storeCtx, key)
+		}
+	}
+}
+
+// abort 设置错误响应并中止执行链
+func abort(ctx *rest.Context, statusCode int, message string) {
+	ctx.SetStatusCode(statusCode)
+	ctx.SetResult(message)
+	ctx.Abort()
+}
+
+// requestFingerprint 计算请求指纹，由请求方法、路径和请求体的 SHA-256 组成
+// 请求体会被读入 ctx.Body，处理器仍可以正常读取
+func requestFingerprint(ctx *rest.Context) string {
+	body := sha256.Sum256(ctx.FillBody())
+	return ctx.Method + " " + ctx.Endpoint + " " + hex.EncodeToString(body[:])
+}
+
+// replay 将保存的响应写回上下文
+func replay(ctx *rest.Context, record *Record) {
+	for key, values := range record.Headers {
+		ctx.Headers[key] = slices.Clone(values)
+	}
+	ctx.Response.Header(ReplayedHeader, "true")
+	ctx.SetStatusCode(record.StatusCode)
+	ctx.SetStatus(record.Status)
+	ctx.SetResult(record.Result)
+}
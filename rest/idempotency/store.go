@@ -0,0 +1,100 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrInFlight 相同键的请求仍在处理中
+var ErrInFlight = errors.New("idempotency: request in flight")
+
+// Record 首次请求的响应，重复请求时原样返回
+type Record struct {
+	Fingerprint string // 首次请求的指纹，重复请求的指纹不同时不会重放
+	StatusCode  int
+	Status      any // 对应 ctx.Status，如业务错误码
+	Headers     http.Header
+	Result      any
+}
+
+// Store 幂等记录存储，接入 Redis 等外部存储时实现该接口即可
+// Result 和 Status 为任意类型，外部存储需要自行序列化
+type Store interface {
+	// Begin 占用 key，lock 为处理期间占用的最长时间
+	// key 已有完成的记录时返回该记录；仍在处理中时返回 ErrInFlight；占用成功时返回 nil, nil
+	Begin(ctx context.Context, key string, lock time.Duration) (*Record, error)
+	// Complete 保存 key 对应的响应，记录在 ttl 后过期
+	Complete(ctx context.Context, key string, record *Record, ttl time.Duration) error
+	// Release 放弃占用 key，后续请求可以重新处理
+	Release(ctx context.Context, key string) error
+}
+
+// memoryEntry 内存存储中的条目，record 为 nil 表示仍在处理中
+type memoryEntry struct {
+	record    *Record
+	expiresAt time.Time
+}
+
+// MemoryStore 基于内存的存储，仅适用于单实例部署
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+}
+
+// NewMemoryStore 创建内存存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries:   make(map[string]memoryEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+// Begin 实现 Store 接口
+func (s *MemoryStore) Begin(_ context.Context, key string, lock time.Duration) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expiresAt) {
+		if entry.record == nil {
+			return nil, ErrInFlight
+		}
+		return entry.record, nil
+	}
+	s.entries[key] = memoryEntry{record: nil, expiresAt: now.Add(lock)}
+	return nil, nil
+}
+
+// Complete 实现 Store 接口
+func (s *MemoryStore) Complete(_ context.Context, key string, record *Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = memoryEntry{record: record, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+// Release 实现 Store 接口
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+// sweep 每分钟最多清理一次过期条目，调用方需持有锁
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
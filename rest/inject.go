@@ -0,0 +1,77 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// contextType context.Context 的反射类型
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// provider 依赖提供者，每次注入时调用 factory 获取实例
+type provider struct {
+	factory func() any
+}
+
+// Provide 为服务器注册类型 T 的依赖提供者
+// 每次注入时都会调用 factory 获取实例，带有 `inject:""` 标签且类型为 T 的字段会被自动填充
+//
+// 使用示例:
+//
+//	rest.Provide(server, func() *gorm.DB { return repo.DB })
+func Provide[T any](s *Server, factory func() T) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	s.providers.Set(t, provider{
+		factory: func() any { return factory() },
+	})
+}
+
+// ProvideValue 为服务器注册类型 T 的单例依赖
+// 所有请求注入的都是同一个 value
+//
+// 使用示例:
+//
+//	rest.ProvideValue(server, config.GlobalConfig)
+func ProvideValue[T any](s *Server, value T) {
+	Provide(s, func() T { return value })
+}
+
+// resolveDependency 根据字段类型查找依赖并设置到字段上
+func resolveDependency(ctx *Context, fieldValue reflect.Value, fieldType reflect.Type) error {
+	if ctx.Server == nil {
+		return fmt.Errorf("no server available to inject %s", fieldType)
+	}
+	p, ok := ctx.Server.providers.Get(fieldType)
+	if !ok && fieldType == contextType {
+		// 未注册时 context.Context 默认注入请求的 context，便于在 Do() 中感知超时和取消
+		fieldValue.Set(reflect.ValueOf(ctx.Context()))
+		return nil
+	}
+	if !ok {
+		// 未注册依赖属于编码错误，不应作为参数错误返回给客户端
+		panic(fmt.Sprintf("rest: no provider registered for type %s", fieldType))
+	}
+	value := p.factory()
+	if value == nil {
+		fieldValue.Set(reflect.Zero(fieldType))
+		return nil
+	}
+	return setAnyValue(fieldValue, value)
+}
+
+// checkDependencies 检查处理器需要注入的依赖是否都已注册，未注册时 panic
+// 在路由注册到路由表时调用，使缺少依赖在启动时暴露，而不是在每个请求中 panic
+func (s *Server) checkDependencies(handlers []HandlerFunc, route string) {
+	for _, handler := range handlers {
+		dependencies, ok := handlerDependencyRegistry.Load(reflect.ValueOf(handler).Pointer())
+		if !ok {
+			continue
+		}
+		for _, t := range dependencies.([]reflect.Type) {
+			if _, ok := s.providers.Get(t); !ok && t != contextType {
+				panic(fmt.Sprintf("rest: no provider registered for type %s required by %s", t, route))
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/akagiyui/go-together/rest"
+)
+
+// UnmatchedRoute 未命中任何路由的请求使用的 route 标签值，避免原始路径导致标签基数爆炸
+const UnmatchedRoute = "<unmatched>"
+
+// OtherMethod 非标准 HTTP 方法使用的 method 标签值，避免任意方法名导致标签基数爆炸
+const OtherMethod = "OTHER"
+
+// HTTPMetrics HTTP 请求指标
+type HTTPMetrics struct {
+	requests *Counter   // http_requests_total{method,route,status}
+	duration *Histogram // http_request_duration_seconds{method,route}
+	inFlight *Gauge     // http_requests_in_flight{method,route}
+}
+
+// NewHTTPMetrics 在 registry 中注册 HTTP 请求指标，buckets 为 nil 时使用 DefaultBuckets
+func NewHTTPMetrics(registry *Registry, buckets []float64) *HTTPMetrics {
+	return &HTTPMetrics{
+		requests: registry.NewCounter("http_requests_total", "Total number of HTTP requests.", "method", "route", "status"),
+		duration: registry.NewHistogram("http_request_duration_seconds", "HTTP request latency in seconds.", buckets, "method", "route"),
+		inFlight: registry.NewGauge("http_requests_in_flight", "Number of HTTP requests currently being served.", "method", "route"),
+	}
+}
+
+// Middleware 记录请求数、耗时和并发数的中间件，应尽量放在中间件链的最前面
+// 状态码和耗时在响应写出后记录，取自实际写出的响应，包括 OnResponse 钩子修改后的状态码、
+// 静态文件返回的 206、304 以及反向代理转发的上游状态码
+//
+// 使用示例:
+//
+//	registry := metrics.NewRegistry()
+//	httpMetrics := metrics.NewHTTPMetrics(registry, nil)
+//	server.Use(httpMetrics.Middleware())
+//	server.Get("/metrics", metrics.Handler(registry))
+func (m *HTTPMetrics) Middleware() rest.HandlerFunc {
+	return func(ctx *rest.Context) {
+		route := ctx.Pattern
+		if route == "" {
+			route = UnmatchedRoute
+		}
+		method := methodLabel(ctx.Method)
+
+		writer := ctx.StatusWriter()
+
+		m.inFlight.Inc(method, route)
+		start := time.Now()
+		ctx.AfterResponse(func() {
+			m.inFlight.Dec(method, route)
+			m.duration.Observe(time.Since(start).Seconds(), method, route)
+			m.requests.Inc(method, route, strconv.Itoa(writer.Status()))
+		})
+
+		ctx.Next()
+	}
+}
+
+// methodLabel 将请求方法转换为 method 标签值，非标准方法统一为 OtherMethod
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return OtherMethod
+}
+
+// Handler 以 Prometheus 文本格式输出 registry 中所有指标的处理器
+func Handler(registry *Registry) rest.HandlerFunc {
+	return func(ctx *rest.Context) {
+		ctx.Response.Header("Content-Type", ContentType)
+		ctx.SetResult(registry.Text())
+	}
+}
@@ -0,0 +1,278 @@
+// Package metrics 提供兼容 Prometheus 文本格式的指标收集，不依赖任何外部服务
+package metrics
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets 默认的直方图分桶，单位为秒，与 Prometheus 客户端一致
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// 指标类型
+const (
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+)
+
+// labelSeparator 拼接标签值作为序列键时使用的分隔符
+const labelSeparator = "\xff"
+
+// metric 所有指标的公共部分
+type metric struct {
+	name       string
+	help       string
+	metricType string
+	labelNames []string
+}
+
+// collector 可输出的指标
+type collector interface {
+	describe() *metric
+	write(b *strings.Builder)
+}
+
+// Registry 指标注册表
+type Registry struct {
+	mu         sync.RWMutex
+	collectors []collector
+	names      map[string]struct{}
+}
+
+// NewRegistry 创建新的指标注册表
+func NewRegistry() *Registry {
+	return &Registry{
+		collectors: make([]collector, 0),
+		names:      make(map[string]struct{}),
+	}
+}
+
+// register 注册指标，名称重复时 panic
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	name := c.describe().name
+	if _, exists := r.names[name]; exists {
+		panic(fmt.Sprintf("metrics: duplicate metric %q", name))
+	}
+	r.names[name] = struct{}{}
+	r.collectors = append(r.collectors, c)
+}
+
+// Text 以 Prometheus 文本格式输出所有指标
+func (r *Registry) Text() string {
+	r.mu.RLock()
+	collectors := slices.Clone(r.collectors)
+	r.mu.RUnlock()
+
+	var b strings.Builder
+	for _, c := range collectors {
+		m := c.describe()
+		fmt.Fprintf(&b, "# HELP %s %s\n", m.name, escapeHelp(m.help))
+		fmt.Fprintf(&b, "# TYPE %s %s\n", m.name, m.metricType)
+		c.write(&b)
+	}
+	return b.String()
+}
+
+// series 一组标签值对应的数据
+type series[T any] struct {
+	labelValues []string
+	value       T
+}
+
+// vec 按标签值分组的序列集合
+type vec[T any] struct {
+	metric
+	mu     sync.Mutex
+	series map[string]*series[T]
+	init   func() T
+}
+
+// with 获取或创建标签值对应的序列，调用方需持有锁
+func (v *vec[T]) with(labelValues []string) *series[T] {
+	if len(labelValues) != len(v.labelNames) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", v.name, len(v.labelNames), len(labelValues)))
+	}
+	key := strings.Join(labelValues, labelSeparator)
+	s, ok := v.series[key]
+	if !ok {
+		s = &series[T]{labelValues: slices.Clone(labelValues), value: v.init()}
+		v.series[key] = s
+	}
+	return s
+}
+
+// sorted 按标签值排序返回所有序列，保证输出稳定，调用方需持有锁
+func (v *vec[T]) sorted() []*series[T] {
+	keys := make([]string, 0, len(v.series))
+	for key := range v.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	result := make([]*series[T], len(keys))
+	for i, key := range keys {
+		result[i] = v.series[key]
+	}
+	return result
+}
+
+// describe 实现 collector 接口
+func (v *vec[T]) describe() *metric {
+	return &v.metric
+}
+
+// Counter 只增不减的计数器
+type Counter struct {
+	vec[float64]
+}
+
+// NewCounter 创建并注册计数器
+func (r *Registry) NewCounter(name, help string, labelNames ...string) *Counter {
+	c := &Counter{vec[float64]{
+		metric: metric{name: name, help: help, metricType: typeCounter, labelNames: labelNames},
+		series: make(map[string]*series[float64]),
+		init:   func() float64 { return 0 },
+	}}
+	r.register(c)
+	return c
+}
+
+// Add 为指定标签值的序列增加 delta，delta 不能为负数
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic("metrics: counter cannot decrease")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.with(labelValues).value += delta
+}
+
+// Inc 为指定标签值的序列加 1
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// write 实现 collector 接口
+func (c *Counter) write(b *strings.Builder) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, s := range c.sorted() {
+		writeSample(b, c.name, c.labelNames, s.labelValues, "", "", s.value)
+	}
+}
+
+// Gauge 可增可减的仪表
+type Gauge struct {
+	vec[float64]
+}
+
+// NewGauge 创建并注册仪表
+func (r *Registry) NewGauge(name, help string, labelNames ...string) *Gauge {
+	g := &Gauge{vec[float64]{
+		metric: metric{name: name, help: help, metricType: typeGauge, labelNames: labelNames},
+		series: make(map[string]*series[float64]),
+		init:   func() float64 { return 0 },
+	}}
+	r.register(g)
+	return g
+}
+
+// Set 设置指定标签值的序列
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.with(labelValues).value = value
+}
+
+// Add 为指定标签值的序列增加 delta
+func (g *Gauge) Add(delta float64, labelValues ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.with(labelValues).value += delta
+}
+
+// Inc 为指定标签值的序列加 1
+func (g *Gauge) Inc(labelValues ...string) {
+	g.Add(1, labelValues...)
+}
+
+// Dec 为指定标签值的序列减 1
+func (g *Gauge) Dec(labelValues ...string) {
+	g.Add(-1, labelValues...)
+}
+
+// write 实现 collector 接口
+func (g *Gauge) write(b *strings.Builder) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, s := range g.sorted() {
+		writeSample(b, g.name, g.labelNames, s.labelValues, "", "", s.value)
+	}
+}
+
+// histogramValue 直方图单个序列的数据
+type histogramValue struct {
+	counts []uint64 // 每个分桶的计数（非累计）
+	sum    float64
+	count  uint64
+}
+
+// Histogram 直方图
+type Histogram struct {
+	vec[*histogramValue]
+	buckets []float64
+}
+
+// NewHistogram 创建并注册直方图，buckets 为 nil 时使用 DefaultBuckets
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = slices.Clone(buckets)
+	sort.Float64s(buckets)
+	h := &Histogram{
+		vec: vec[*histogramValue]{
+			metric: metric{name: name, help: help, metricType: typeHistogram, labelNames: labelNames},
+			series: make(map[string]*series[*histogramValue]),
+			init: func() *histogramValue {
+				return &histogramValue{counts: make([]uint64, len(buckets))}
+			},
+		},
+		buckets: buckets,
+	}
+	r.register(h)
+	return h
+}
+
+// Observe 记录一次观测值
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := h.with(labelValues).value
+	if i := sort.SearchFloat64s(h.buckets, value); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.sum += value
+	s.count++
+}
+
+// write 实现 collector 接口
+func (h *Histogram) write(b *strings.Builder) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, s := range h.sorted() {
+		var cumulative uint64
+		for i, upperBound := range h.buckets {
+			cumulative += s.value.counts[i]
+			writeSample(b, h.name+"_bucket", h.labelNames, s.labelValues, "le", formatFloat(upperBound), float64(cumulative))
+		}
+		writeSample(b, h.name+"_bucket", h.labelNames, s.labelValues, "le", "+Inf", float64(s.value.count))
+		writeSample(b, h.name+"_sum", h.labelNames, s.labelValues, "", "", s.value.sum)
+		writeSample(b, h.name+"_count", h.labelNames, s.labelValues, "", "", float64(s.value.count))
+	}
+}
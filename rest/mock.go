@@ -0,0 +1,175 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ResponseDeclarer 声明 Do() 返回值的类型，mock 模式下根据该类型生成示例响应
+// 返回该类型的零值即可，如 repo.User{} 或 []repo.Audio(nil)
+//
+// 生成示例时，结构体字段可以通过 `example:"..."` 标签指定示例值
+type ResponseDeclarer interface {
+	Response() any
+}
+
+// MockOptions mock 模式配置
+type MockOptions struct {
+	// Fixtures 示例响应文件，文件名为请求结构体的类型名加 .json，如 GetUserMeRequest.json
+	// 存在对应文件时优先于根据 ResponseDeclarer 生成的示例
+	Fixtures fs.FS
+}
+
+// MockHeader mock 模式下的响应会携带该响应头
+const MockHeader = "X-Mock"
+
+// EnableMock 开启 mock 模式，通过 Service[T] 注册的路由不再调用 Do()，而是返回示例响应
+// 参数绑定和校验仍然正常执行；没有示例时返回 501
+func (s *Server) EnableMock(options MockOptions) {
+	s.mock = &options
+}
+
+// mockResponse 返回请求类型 t 的示例响应
+func (m *MockOptions) mockResponse(ctx *Context, t reflect.Type, handler any) {
+	ctx.Response.Header(MockHeader, "true")
+
+	if m.Fixtures != nil {
+		content, err := fs.ReadFile(m.Fixtures, t.Name()+".json")
+		switch {
+		case err == nil:
+			if !json.Valid(content) {
+				ctx.SetStatusCode(http.StatusInternalServerError)
+				ctx.SetResult("Invalid mock fixture: " + t.Name() + ".json")
+				return
+			}
+			ctx.SetResult(json.RawMessage(content))
+			return
+		case !errors.Is(err, fs.ErrNotExist):
+			ctx.SetStatusCode(http.StatusInternalServerError)
+			ctx.SetResult("Failed to read mock fixture: " + err.Error())
+			return
+		}
+	}
+
+	if declarer, ok := handler.(ResponseDeclarer); ok {
+		ctx.SetResult(Example(declarer.Response()))
+		return
+	}
+	ctx.SetStatusCode(http.StatusNotImplemented)
+	ctx.SetResult("Not Implemented: no mock response for " + t.Name())
+}
+
+// exampleTime 示例中使用的固定时间，保证示例响应稳定
+var exampleTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Example 根据 value 的类型生成示例值，value 中已有的非零值会被保留
+// 字符串为字段的 JSON 名称，数字为 1，布尔值为 true，切片和 map 包含一个元素，指针会被分配，
+// any 类型的字段按照其中实际存放的值的类型填充，如 model.PageData{List: []repo.Audio{}}
+func Example(value any) any {
+	if value == nil {
+		return nil
+	}
+	example := reflect.New(reflect.TypeOf(value)).Elem()
+	example.Set(reflect.ValueOf(value))
+	fillExample(example, "string", make(map[reflect.Type]bool))
+	return example.Interface()
+}
+
+// fillExample 递归填充示例值中的零值，visiting 用于避免自引用类型无限递归
+func fillExample(v reflect.Value, name string, visiting map[reflect.Type]bool) {
+	t := v.Type()
+	if t.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		fillExample(elem, name, visiting)
+		v.Set(elem)
+		return
+	}
+	if t.Kind() != reflect.Struct && t.Kind() != reflect.Array && !v.IsZero() && !isEmptyCollection(v) {
+		return
+	}
+
+	if t == reflect.TypeOf(time.Time{}) {
+		v.Set(reflect.ValueOf(exampleTime))
+		return
+	}
+	if t == reflect.TypeOf(json.RawMessage{}) {
+		v.Set(reflect.ValueOf(json.RawMessage("{}")))
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(name)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1.5)
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Ptr:
+		if visiting[t.Elem()] {
+			return
+		}
+		elem := reflect.New(t.Elem())
+		fillExample(elem.Elem(), name, visiting)
+		v.Set(elem)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 || visiting[t.Elem()] {
+			return
+		}
+		slice := reflect.MakeSlice(t, 1, 1)
+		fillExample(slice.Index(0), name, visiting)
+		v.Set(slice)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			fillExample(v.Index(i), name, visiting)
+		}
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String || visiting[t.Elem()] {
+			return
+		}
+		m := reflect.MakeMap(t)
+		key := reflect.New(t.Key()).Elem()
+		key.SetString("key")
+		elem := reflect.New(t.Elem()).Elem()
+		fillExample(elem, name, visiting)
+		m.SetMapIndex(key, elem)
+		v.Set(m)
+	case reflect.Struct:
+		visiting[t] = true
+		defer delete(visiting, t)
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fieldName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if fieldName == "-" {
+				continue
+			}
+			if fieldName == "" {
+				fieldName = field.Name
+			}
+			if example, ok := field.Tag.Lookup("example"); ok && setFieldValue(v.Field(i), example) == nil {
+				continue
+			}
+			fillExample(v.Field(i), fieldName, visiting)
+		}
+	}
+}
+
+// isEmptyCollection 检查是否为空的切片或 map
+func isEmptyCollection(v reflect.Value) bool {
+	return (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0
+}
@@ -0,0 +1,212 @@
+package rest
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FilterType 过滤字段的值类型
+type FilterType int
+
+const (
+	// FilterString 字符串
+	FilterString FilterType = iota
+	// FilterInt 整数，值为 int64
+	FilterInt
+	// FilterFloat 浮点数，值为 float64
+	FilterFloat
+	// FilterBool 布尔值
+	FilterBool
+	// FilterTime RFC 3339 时间，值为 time.Time
+	FilterTime
+)
+
+// FilterOp 过滤操作符
+type FilterOp string
+
+const (
+	OpEq   FilterOp = "eq"   // 等于
+	OpNe   FilterOp = "ne"   // 不等于
+	OpGt   FilterOp = "gt"   // 大于
+	OpGte  FilterOp = "gte"  // 大于等于
+	OpLt   FilterOp = "lt"   // 小于
+	OpLte  FilterOp = "lte"  // 小于等于
+	OpIn   FilterOp = "in"   // 属于，值以逗号分隔
+	OpNin  FilterOp = "nin"  // 不属于，值以逗号分隔
+	OpLike FilterOp = "like" // 包含子串，仅字符串
+	OpNull FilterOp = "null" // 为空（true）或不为空（false）
+)
+
+// Filter 单个过滤条件
+// Value 的类型由字段类型决定：string、int64、float64、bool 或 time.Time
+// 操作符为 in 和 nin 时使用 Values；操作符为 null 时 Value 为 bool
+type Filter struct {
+	Field  string
+	Op     FilterOp
+	Type   FilterType
+	Value  any
+	Values []any
+}
+
+// parseFilterType 解析 page 标签中的字段类型，为空时默认为字符串
+func parseFilterType(name string) (FilterType, bool) {
+	switch name {
+	case "", "string":
+		return FilterString, true
+	case "int":
+		return FilterInt, true
+	case "float":
+		return FilterFloat, true
+	case "bool":
+		return FilterBool, true
+	case "time":
+		return FilterTime, true
+	}
+	return 0, false
+}
+
+// parseFilters 解析 filter[field][op]=value 形式的查询参数，结果按字段和操作符排序
+func parseFilters(query url.Values, allowed map[string]FilterType) ([]Filter, error) {
+	filters := make([]Filter, 0)
+	for key, values := range query {
+		rest, ok := strings.CutPrefix(key, FilterParam+"[")
+		if !ok {
+			continue
+		}
+		field, rest, ok := strings.Cut(rest, "]")
+		if !ok || field == "" {
+			return nil, fmt.Errorf("%s: invalid parameter %q", FilterParam, key)
+		}
+		op := OpEq
+		if rest != "" {
+			name, ok := strings.CutPrefix(rest, "[")
+			if !ok || !strings.HasSuffix(name, "]") {
+				return nil, fmt.Errorf("%s: invalid parameter %q", FilterParam, key)
+			}
+			op = FilterOp(strings.TrimSuffix(name, "]"))
+		}
+
+		filterType, ok := allowed[field]
+		if !ok {
+			return nil, fmt.Errorf("%s: field %q is not filterable", FilterParam, field)
+		}
+		filter, err := newFilter(field, op, filterType, values)
+		if err != nil {
+			return nil, fmt.Errorf("%s[%s][%s]: %w", FilterParam, field, op, err)
+		}
+		filters = append(filters, filter)
+	}
+
+	slices.SortFunc(filters, func(a, b Filter) int {
+		if c := strings.Compare(a.Field, b.Field); c != 0 {
+			return c
+		}
+		return strings.Compare(string(a.Op), string(b.Op))
+	})
+	return filters, nil
+}
+
+// newFilter 检查操作符并按字段类型转换值
+func newFilter(field string, op FilterOp, filterType FilterType, values []string) (Filter, error) {
+	filter := Filter{Field: field, Op: op, Type: filterType}
+	value := ""
+	if len(values) > 0 {
+		value = values[len(values)-1]
+	}
+
+	switch op {
+	case OpNull:
+		isNull, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid bool %q", value)
+		}
+		filter.Value = isNull
+		return filter, nil
+	case OpIn, OpNin:
+		for _, item := range values {
+			for _, part := range splitList(item) {
+				converted, err := convertFilterValue(filterType, part)
+				if err != nil {
+					return filter, err
+				}
+				filter.Values = append(filter.Values, converted)
+			}
+		}
+		return filter, nil
+	case OpLike:
+		if filterType != FilterString {
+			return filter, fmt.Errorf("operator like is only supported for strings")
+		}
+	case OpGt, OpGte, OpLt, OpLte:
+		if filterType == FilterBool {
+			return filter, fmt.Errorf("operator %s is not supported for bool", op)
+		}
+	case OpEq, OpNe:
+	default:
+		return filter, fmt.Errorf("unknown operator %q", op)
+	}
+
+	converted, err := convertFilterValue(filterType, value)
+	if err != nil {
+		return filter, err
+	}
+	filter.Value = converted
+	return filter, nil
+}
+
+// convertFilterValue 将字符串转换为字段类型对应的值
+func convertFilterValue(filterType FilterType, value string) (any, error) {
+	switch filterType {
+	case FilterInt:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid int %q", value)
+		}
+		return n, nil
+	case FilterFloat:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid float %q", value)
+		}
+		return f, nil
+	case FilterBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bool %q", value)
+		}
+		return b, nil
+	case FilterTime:
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %q, expected RFC 3339", value)
+		}
+		return t, nil
+	}
+	return value, nil
+}
+
+// encode 将过滤条件编码回查询参数
+func (f Filter) encode(values url.Values) {
+	key := FilterParam + "[" + f.Field + "][" + string(f.Op) + "]"
+	if f.Op == OpIn || f.Op == OpNin {
+		items := make([]string, len(f.Values))
+		for i, value := range f.Values {
+			items[i] = formatFilterValue(value)
+		}
+		values.Set(key, strings.Join(items, ","))
+		return
+	}
+	values.Set(key, formatFilterValue(f.Value))
+}
+
+// formatFilterValue 格式化过滤值
+func formatFilterValue(value any) string {
+	if t, ok := value.(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+	return fmt.Sprint(value)
+}
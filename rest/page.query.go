@@ -0,0 +1,340 @@
+package rest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/akagiyui/go-together/common/cache"
+)
+
+// QueryBinder 自定义查询参数绑定
+// 未带绑定标签的结构体字段实现该接口时，由字段自行解析查询参数，不再递归展开
+// tag 为字段的结构体标签，可用于读取额外配置
+type QueryBinder interface {
+	BindQuery(query url.Values, tag reflect.StructTag) error
+}
+
+// QueryEncoder 自定义查询参数编码，restclient 编码请求时使用，与 QueryBinder 对应
+type QueryEncoder interface {
+	EncodeQuery() url.Values
+}
+
+// PageMode 分页模式
+type PageMode int
+
+const (
+	// PageByOffset 页码分页
+	PageByOffset PageMode = iota
+	// PageByCursor 游标分页
+	PageByCursor
+)
+
+// 分页参数名
+const (
+	PageIndexParam = "page_index"
+	PageSizeParam  = "page_size"
+	CursorParam    = "cursor"
+	SortParam      = "sort"
+	FilterParam    = "filter"
+)
+
+// SortField 排序字段
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// PageQuery 分页、排序和过滤参数，作为请求结构体的字段（不带绑定标签）即可自动绑定
+//
+// 通过 page 标签配置，选项之间用 ; 分隔:
+//
+//	size=20                     默认每页数量，默认为 20
+//	max=100                     每页数量上限，默认为 100
+//	mode=cursor                 未携带任何分页参数时使用游标分页，默认为页码分页
+//	sort=id,name,created_at     允许排序的字段
+//	default=-id                 未指定排序时的默认排序
+//	filter=name,id:int,at:time  允许过滤的字段及其类型（string、int、float、bool、time），默认为 string
+//
+// 请求参数:
+//
+//	?page_index=2&page_size=20                  页码分页
+//	?cursor=xxx&page_size=20                    游标分页，首页使用空的 cursor
+//	?sort=name,-created_at                      按 name 升序、created_at 降序
+//	?filter[name][like]=abc&filter[id][in]=1,2  过滤，省略操作符时为 eq
+//
+// 使用示例:
+//
+//	type ListAudioRequest struct {
+//		Page rest.PageQuery `page:"sort=id,name;default=-id;filter=name,track_id:int"`
+//	}
+type PageQuery struct {
+	Mode   PageMode
+	Page   int    // 页码，从 1 开始，仅页码分页
+	Size   int    // 每页数量
+	Cursor string // 上一页返回的游标，首页为空，仅游标分页
+	Sort   []SortField
+	Filter []Filter // 所有条件需要同时满足
+}
+
+// Offset 页码分页时的偏移量
+func (q PageQuery) Offset() int {
+	if q.Page < 1 {
+		return 0
+	}
+	return (q.Page - 1) * q.Size
+}
+
+// Limit 每页数量
+func (q PageQuery) Limit() int {
+	return q.Size
+}
+
+// DecodeCursor 将游标解码到 out，游标为空时返回 false
+func (q PageQuery) DecodeCursor(out any) (bool, error) {
+	if q.Cursor == "" {
+		return false, nil
+	}
+	return true, DecodeCursor(q.Cursor, out)
+}
+
+// EncodeCursor 将任意可以 JSON 序列化的值编码为不透明的游标
+func EncodeCursor(value any) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor 解码 EncodeCursor 生成的游标
+func DecodeCursor(cursor string, out any) error {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return fmt.Errorf("invalid cursor")
+	}
+	if err := json.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("invalid cursor")
+	}
+	return nil
+}
+
+// pageConfig 解析后的 page 标签
+type pageConfig struct {
+	size        int
+	max         int
+	cursor      bool
+	sort        []string
+	defaultSort []SortField
+	filter      map[string]FilterType
+}
+
+// pageConfigCache page 标签的解析结果
+var pageConfigCache = cache.NewMap[string, *pageConfig]()
+
+// parsePageConfig 解析 page 标签，标签格式错误时 panic
+// 注册路由时已经通过 checkPageTags 解析过，请求时直接命中缓存
+func parsePageConfig(tag string) *pageConfig {
+	return pageConfigCache.GetOrSet(tag, func() *pageConfig {
+		config := &pageConfig{
+			size:   20,
+			max:    100,
+			filter: make(map[string]FilterType),
+		}
+		for _, option := range strings.Split(tag, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+			switch key {
+			case "":
+			case "size", "max":
+				n, err := strconv.Atoi(value)
+				if err != nil || n < 1 {
+					panic(fmt.Sprintf("rest: invalid page tag option %q", option))
+				}
+				if key == "size" {
+					config.size = n
+				} else {
+					config.max = n
+				}
+			case "mode":
+				config.cursor = value == "cursor"
+			case "sort":
+				config.sort = splitList(value)
+			case "default":
+				for _, field := range splitList(value) {
+					config.defaultSort = append(config.defaultSort, parseSortField(field))
+				}
+			case "filter":
+				for _, field := range splitList(value) {
+					name, typeName, _ := strings.Cut(field, ":")
+					filterType, ok := parseFilterType(typeName)
+					if !ok {
+						panic(fmt.Sprintf("rest: invalid filter type %q in page tag", typeName))
+					}
+					config.filter[name] = filterType
+				}
+			default:
+				panic(fmt.Sprintf("rest: unknown page tag option %q", option))
+			}
+		}
+		if config.size > config.max {
+			config.size = config.max
+		}
+		return config
+	})
+}
+
+// checkPageTags 解析结构体中 PageQuery 字段的 page 标签，在注册路由时调用，标签格式错误时 panic
+// 遍历规则与参数绑定一致：只检查未带绑定标签的字段，并递归处理嵌套结构体
+func checkPageTags(t reflect.Type) {
+	checkPageTagsOf(t, make(map[reflect.Type]bool))
+}
+
+// checkPageTagsOf 递归检查 page 标签，visited 用于避免自引用的结构体无限递归
+func checkPageTagsOf(t reflect.Type, visited map[reflect.Type]bool) {
+	if visited[t] {
+		return
+	}
+	visited[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || hasBindingTag(field) {
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType == pageQueryType {
+			func() {
+				defer func() {
+					if recovered := recover(); recovered != nil {
+						panic(fmt.Sprintf("%v in field %s", recovered, field.Name))
+					}
+				}()
+				parsePageConfig(field.Tag.Get("page"))
+			}()
+			continue
+		}
+		// 其他自定义绑定的字段不会递归展开
+		if reflect.PointerTo(fieldType).Implements(queryBinderType) {
+			continue
+		}
+		if fieldType.Kind() == reflect.Struct {
+			checkPageTagsOf(fieldType, visited)
+		}
+	}
+}
+
+var (
+	pageQueryType   = reflect.TypeOf(PageQuery{})
+	queryBinderType = reflect.TypeOf((*QueryBinder)(nil)).Elem()
+)
+
+// BindQuery 实现 QueryBinder 接口
+func (q *PageQuery) BindQuery(query url.Values, tag reflect.StructTag) error {
+	config := parsePageConfig(tag.Get("page"))
+
+	// 分页
+	q.Mode = PageByOffset
+	if _, ok := query[CursorParam]; ok || (config.cursor && !query.Has(PageIndexParam)) {
+		q.Mode = PageByCursor
+		q.Cursor = query.Get(CursorParam)
+	} else {
+		q.Page = 1
+		if value := query.Get(PageIndexParam); value != "" {
+			page, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("%s: %w", PageIndexParam, err)
+			}
+			q.Page = max(page, 1)
+		}
+	}
+	q.Size = config.size
+	if value := query.Get(PageSizeParam); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", PageSizeParam, err)
+		}
+		if size >= 1 {
+			q.Size = min(size, config.max)
+		}
+	}
+
+	// 排序
+	q.Sort = slices.Clone(config.defaultSort)
+	if value := query.Get(SortParam); value != "" {
+		q.Sort = q.Sort[:0]
+		for _, field := range splitList(value) {
+			sortField := parseSortField(field)
+			if !slices.Contains(config.sort, sortField.Field) {
+				return fmt.Errorf("%s: field %q is not sortable", SortParam, sortField.Field)
+			}
+			q.Sort = append(q.Sort, sortField)
+		}
+	}
+
+	// 过滤
+	filters, err := parseFilters(query, config.filter)
+	if err != nil {
+		return err
+	}
+	q.Filter = filters
+	return nil
+}
+
+// EncodeQuery 实现 QueryEncoder 接口
+func (q PageQuery) EncodeQuery() url.Values {
+	values := make(url.Values)
+	if q.Mode == PageByCursor {
+		values.Set(CursorParam, q.Cursor)
+	} else if q.Page > 0 {
+		values.Set(PageIndexParam, strconv.Itoa(q.Page))
+	}
+	if q.Size > 0 {
+		values.Set(PageSizeParam, strconv.Itoa(q.Size))
+	}
+	if len(q.Sort) > 0 {
+		fields := make([]string, len(q.Sort))
+		for i, field := range q.Sort {
+			fields[i] = field.String()
+		}
+		values.Set(SortParam, strings.Join(fields, ","))
+	}
+	for _, filter := range q.Filter {
+		filter.encode(values)
+	}
+	return values
+}
+
+// String 返回排序字段的查询参数形式，如 -created_at
+func (f SortField) String() string {
+	if f.Desc {
+		return "-" + f.Field
+	}
+	return f.Field
+}
+
+// parseSortField 解析排序字段，- 前缀表示降序，+ 前缀表示升序
+func parseSortField(field string) SortField {
+	if name, ok := strings.CutPrefix(field, "-"); ok {
+		return SortField{Field: name, Desc: true}
+	}
+	return SortField{Field: strings.TrimPrefix(field, "+"), Desc: false}
+}
+
+// splitList 按逗号分隔并去除空白和空项
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
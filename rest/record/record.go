@@ -0,0 +1,295 @@
+// Package record 提供请求录制功能，将请求和响应按行写入 JSONL 文件，用于在本地重放和排查线上问题
+//
+// 录制发生在 http.Handler 层，记录的是客户端实际收到的响应（包括 OnResponse 钩子包装后的结果）。
+// 请求头、查询参数、请求体和响应体中的敏感信息在写入文件之前脱敏，脱敏后的值为 Redacted。
+package record
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Redacted 敏感信息脱敏后的值
+const Redacted = "[REDACTED]"
+
+// 默认值
+const (
+	DefaultMaxSize     = 100 << 20 // 单个文件 100 MiB
+	DefaultMaxBackups  = 3
+	DefaultMaxBodySize = 64 << 10 // 请求体和响应体各记录 64 KiB
+)
+
+// 默认脱敏的请求头和响应头
+var defaultRedactHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+}
+
+// 默认脱敏的字段名，同时用于 JSON 请求体、JSON 响应体、表单和查询参数，不区分大小写
+var defaultRedactFields = []string{
+	"password",
+	"secret",
+	"token",
+	"access_token",
+	"refresh_token",
+	"api_key",
+	"access_key",
+	"secret_key",
+}
+
+// Options 录制配置
+type Options struct {
+	// Path 录制文件路径，必填，所在目录不存在时自动创建
+	Path string
+	// MaxSize 单个文件的最大字节数，超过后轮转为 Path.1、Path.2 …，默认为 DefaultMaxSize
+	MaxSize int64
+	// MaxBackups 轮转后保留的历史文件数，默认为 DefaultMaxBackups
+	MaxBackups int
+	// MaxBodySize 请求体和响应体记录的最大字节数，超出部分截断并标记 BodyTruncated 或 ResultTruncated，默认为 DefaultMaxBodySize
+	MaxBodySize int
+
+	// RedactHeaders 需要脱敏的请求头和响应头，在默认列表（Authorization、Cookie、Set-Cookie 等）的基础上追加
+	RedactHeaders []string
+	// RedactFields 需要脱敏的字段名，在默认列表（password、secret、token 等）的基础上追加
+	RedactFields []string
+
+	// Skip 返回 true 时不录制该请求，如健康检查和文件上传
+	Skip func(r *http.Request) bool
+	// ErrorHandler 写入录制文件失败时调用，默认输出到标准输出，录制失败不影响请求处理
+	ErrorHandler func(err error)
+}
+
+// Entry 一次请求和响应的记录，对应录制文件中的一行
+//
+// 请求体和响应体为合法 JSON 且未被截断时原样保存（脱敏后），对应的 Encoding 为空；
+// 否则保存为 JSON 字符串，Encoding 为 "text" 或 "base64"。
+type Entry struct {
+	Time       time.Time   `json:"time"`
+	DurationMS float64     `json:"duration_ms"`
+	Method     string      `json:"method"`
+	Path       string      `json:"path"` // 包含查询参数的请求 URI
+	Header     http.Header `json:"header,omitempty"`
+
+	Body          json.RawMessage `json:"body,omitempty"`
+	BodyEncoding  string          `json:"body_encoding,omitempty"`
+	BodyTruncated bool            `json:"body_truncated,omitempty"` // 请求体超过 MaxBodySize 被截断，无法重放
+
+	Status         int         `json:"status"`
+	ResponseHeader http.Header `json:"response_header,omitempty"`
+
+	Result          json.RawMessage `json:"result,omitempty"`
+	ResultEncoding  string          `json:"result_encoding,omitempty"`
+	ResultTruncated bool            `json:"result_truncated,omitempty"` // 响应体超过 MaxBodySize 被截断
+}
+
+// RequestBody 返回解码后的请求体
+func (e *Entry) RequestBody() ([]byte, error) {
+	return decodeBody(e.Body, e.BodyEncoding)
+}
+
+// ResponseBody 返回解码后的响应体
+func (e *Entry) ResponseBody() ([]byte, error) {
+	return decodeBody(e.Result, e.ResultEncoding)
+}
+
+// Recorder 请求录制器，并发安全
+type Recorder struct {
+	options  Options
+	redactor redactor
+
+	mu   sync.Mutex
+	file *rotatingFile
+}
+
+// New 创建录制器并打开录制文件，不再使用时需要调用 Close
+func New(options Options) (*Recorder, error) {
+	if options.Path == "" {
+		return nil, errors.New("record: Path is required")
+	}
+	if options.MaxSize <= 0 {
+		options.MaxSize = DefaultMaxSize
+	}
+	if options.MaxBackups <= 0 {
+		options.MaxBackups = DefaultMaxBackups
+	}
+	if options.MaxBodySize <= 0 {
+		options.MaxBodySize = DefaultMaxBodySize
+	}
+	if options.ErrorHandler == nil {
+		options.ErrorHandler = func(err error) {
+			fmt.Printf("record: %v\n", err)
+		}
+	}
+
+	file, err := openRotatingFile(options.Path, options.MaxSize, options.MaxBackups)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{
+		options:  options,
+		redactor: newRedactor(options.RedactHeaders, options.RedactFields),
+		file:     file,
+	}, nil
+}
+
+// Handler 包装 http.Handler，录制经过的每个请求
+//
+// 使用示例:
+//
+//	recorder, err := record.New(record.Options{Path: "captures/traffic.jsonl"})
+//	if err != nil {
+//	    panic(err)
+//	}
+//	defer recorder.Close()
+//
+//	server.RunWithOptions(rest.RunOptions{
+//	    ConfigureServer: func(srv *http.Server) {
+//	        srv.Handler = recorder.Handler(srv.Handler)
+//	    },
+//	})
+func (rec *Recorder) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rec.options.Skip != nil && rec.options.Skip(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		entry := &Entry{
+			Time:   start,
+			Method: r.Method,
+			Path:   rec.redactor.uri(r.URL),
+			Header: rec.redactor.header(r.Header),
+		}
+
+		// 预先读取不超过 MaxBodySize 的请求体，剩余部分由处理器继续读取
+		if r.Body != nil && r.Body != http.NoBody {
+			head, err := io.ReadAll(io.LimitReader(r.Body, int64(rec.options.MaxBodySize)+1))
+			truncated := len(head) > rec.options.MaxBodySize
+			r.Body = &prefixedBody{Reader: io.MultiReader(bytes.NewReader(head), r.Body), Closer: r.Body}
+			if err == nil {
+				if truncated {
+					head = head[:rec.options.MaxBodySize]
+				}
+				entry.Body, entry.BodyEncoding = rec.redactor.body(head, r.Header.Get("Content-Type"), truncated)
+				entry.BodyTruncated = truncated
+			}
+		}
+
+		rw := &responseRecorder{ResponseWriter: w, limit: rec.options.MaxBodySize}
+		next.ServeHTTP(rw, r)
+
+		entry.DurationMS = float64(time.Since(start).Microseconds()) / 1000
+		entry.Status, entry.ResponseHeader = rw.status, rw.header
+		if rw.status == 0 {
+			// 处理器没有写出任何内容，net/http 会返回 200
+			entry.Status, entry.ResponseHeader = http.StatusOK, w.Header().Clone()
+		}
+		entry.ResponseHeader = rec.redactor.header(entry.ResponseHeader)
+		entry.ResultTruncated = rw.written > int64(rw.body.Len())
+		entry.Result, entry.ResultEncoding = rec.redactor.body(rw.body.Bytes(), entry.ResponseHeader.Get("Content-Type"), entry.ResultTruncated)
+
+		rec.write(entry)
+	})
+}
+
+// Close 关闭录制文件
+func (rec *Recorder) Close() error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return rec.file.Close()
+}
+
+// write 将记录写入文件，失败时交给 ErrorHandler
+func (rec *Recorder) write(entry *Entry) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		rec.options.ErrorHandler(err)
+		return
+	}
+	line = append(line, '\n')
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if _, err := rec.file.Write(line); err != nil {
+		rec.options.ErrorHandler(err)
+	}
+}
+
+// prefixedBody 已预读部分内容的请求体
+type prefixedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// responseRecorder 在写出响应的同时记录状态码、响应头和不超过 limit 的响应体
+type responseRecorder struct {
+	http.ResponseWriter
+	limit   int
+	status  int
+	header  http.Header
+	body    bytes.Buffer
+	written int64
+}
+
+func (w *responseRecorder) WriteHeader(statusCode int) {
+	// 1xx 信息响应之后还会有最终响应
+	if w.status == 0 && statusCode >= http.StatusOK {
+		w.status = statusCode
+		w.header = w.ResponseWriter.Header().Clone()
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if remaining := w.limit - w.body.Len(); remaining > 0 {
+		w.body.Write(b[:min(len(b), remaining)])
+	}
+	w.written += int64(len(b))
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush 支持流式响应
+func (w *responseRecorder) Flush() {
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap 供 http.ResponseController 访问底层的 ResponseWriter
+func (w *responseRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// decodeBody 按照编码方式还原请求体或响应体
+func decodeBody(raw json.RawMessage, encoding string) ([]byte, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	switch encoding {
+	case "":
+		return raw, nil
+	case encodingText, encodingBase64:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, err
+		}
+		if encoding == encodingText {
+			return []byte(s), nil
+		}
+		return base64.StdEncoding.DecodeString(s)
+	default:
+		return nil, fmt.Errorf("record: unknown body encoding %q", encoding)
+	}
+}
@@ -0,0 +1,140 @@
+package record
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// 非 JSON 内容的编码方式
+const (
+	encodingText   = "text"
+	encodingBase64 = "base64"
+)
+
+// redactor 按照请求头和字段名脱敏
+type redactor struct {
+	headers map[string]bool // 规范化的头部名称
+	fields  map[string]bool // 小写的字段名
+}
+
+// newRedactor 创建在默认列表基础上追加指定请求头和字段名的脱敏器
+func newRedactor(headers, fields []string) redactor {
+	r := redactor{headers: make(map[string]bool), fields: make(map[string]bool)}
+	for _, name := range append(append([]string{}, defaultRedactHeaders...), headers...) {
+		r.headers[http.CanonicalHeaderKey(name)] = true
+	}
+	for _, name := range append(append([]string{}, defaultRedactFields...), fields...) {
+		r.fields[strings.ToLower(name)] = true
+	}
+	return r
+}
+
+// header 复制头部并替换敏感的值
+func (r redactor) header(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+	result := header.Clone()
+	for key, values := range result {
+		if r.headers[http.CanonicalHeaderKey(key)] {
+			for i := range values {
+				values[i] = Redacted
+			}
+		}
+	}
+	return result
+}
+
+// uri 返回请求 URI，替换敏感的查询参数
+func (r redactor) uri(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.RequestURI()
+	}
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil || !r.values(query) {
+		return u.RequestURI()
+	}
+	redacted := *u
+	redacted.RawQuery = query.Encode()
+	return redacted.RequestURI()
+}
+
+// values 替换敏感的表单或查询参数，返回是否有参数被替换
+func (r redactor) values(values url.Values) bool {
+	changed := false
+	for key, list := range values {
+		if r.fields[strings.ToLower(key)] {
+			for i := range list {
+				list[i] = Redacted
+			}
+			changed = true
+		}
+	}
+	return changed
+}
+
+// body 脱敏并编码请求体或响应体
+// 未截断的 JSON 原样保存，表单和其他文本保存为字符串，二进制内容保存为 base64
+func (r redactor) body(body []byte, contentType string, truncated bool) (json.RawMessage, string) {
+	if len(body) == 0 {
+		return nil, ""
+	}
+	if !truncated {
+		if json.Valid(body) {
+			return r.json(body), ""
+		}
+		if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "application/x-www-form-urlencoded" {
+			if values, err := url.ParseQuery(string(body)); err == nil && r.values(values) {
+				body = []byte(values.Encode())
+			}
+		}
+	}
+	if utf8.Valid(body) {
+		encoded, _ := json.Marshal(string(body))
+		return encoded, encodingText
+	}
+	encoded, _ := json.Marshal(base64.StdEncoding.EncodeToString(body))
+	return encoded, encodingBase64
+}
+
+// json 替换 JSON 中所有敏感字段的值，没有敏感字段时保持原样
+func (r redactor) json(body []byte) json.RawMessage {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber() // 保留数字的原始精度
+	var value any
+	if err := decoder.Decode(&value); err != nil || !r.walk(value) {
+		return body
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return encoded
+}
+
+// walk 递归替换对象中的敏感字段，返回是否有字段被替换
+func (r redactor) walk(value any) bool {
+	changed := false
+	switch value := value.(type) {
+	case map[string]any:
+		for key, child := range value {
+			if r.fields[strings.ToLower(key)] {
+				value[key] = Redacted
+				changed = true
+				continue
+			}
+			changed = r.walk(child) || changed
+		}
+	case []any:
+		for _, child := range value {
+			changed = r.walk(child) || changed
+		}
+	}
+	return changed
+}
@@ -0,0 +1,56 @@
+package respcache
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cacheControl 解析后的 Cache-Control 指令
+type cacheControl struct {
+	noStore bool
+	noCache bool
+	private bool
+
+	maxAge             time.Duration
+	hasMaxAge          bool
+	sharedMaxAge       time.Duration // s-maxage
+	hasSharedMaxAge    bool
+	staleRevalidate    time.Duration // stale-while-revalidate
+	hasStaleRevalidate bool
+}
+
+// parseCacheControl 解析 Cache-Control 头，忽略无法识别的指令
+func parseCacheControl(value string) cacheControl {
+	var cc cacheControl
+	for _, directive := range strings.Split(value, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		arg = strings.Trim(strings.TrimSpace(arg), `"`)
+
+		switch name {
+		case "no-store":
+			cc.noStore = true
+		case "no-cache":
+			cc.noCache = true
+		case "private":
+			cc.private = true
+		case "max-age":
+			cc.maxAge, cc.hasMaxAge = parseSeconds(arg)
+		case "s-maxage":
+			cc.sharedMaxAge, cc.hasSharedMaxAge = parseSeconds(arg)
+		case "stale-while-revalidate":
+			cc.staleRevalidate, cc.hasStaleRevalidate = parseSeconds(arg)
+		}
+	}
+	return cc
+}
+
+// parseSeconds 解析以秒为单位的非负整数
+func parseSeconds(value string) (time.Duration, bool) {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
@@ -0,0 +1,247 @@
+// Package respcache 提供 GET 路由的服务端响应缓存中间件
+//
+// 缓存以路由名称、查询参数和指定的请求头作为键，支持 TTL、Cache-Control、
+// stale-while-revalidate 以及按路由名称或标签主动失效。
+package respcache
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akagiyui/go-together/rest"
+)
+
+// StateHeader 响应头，表示响应来自缓存的状态：HIT、STALE 或 MISS
+const StateHeader = "X-Cache"
+
+// 缓存状态
+const (
+	StateHit   = "HIT"
+	StateStale = "STALE"
+	StateMiss  = "MISS"
+)
+
+// Options 缓存中间件配置
+type Options struct {
+	// Name 路由名称，用于 InvalidateRoute，默认为路由路径（ctx.Pattern）
+	Name string
+	// TTL 响应保持新鲜的时间，处理器设置的 Cache-Control: s-maxage / max-age 优先
+	TTL time.Duration
+	// StaleWhileRevalidate 过期后仍可返回旧响应的时间，期间会在后台刷新缓存
+	// 处理器设置的 Cache-Control: stale-while-revalidate 优先
+	StaleWhileRevalidate time.Duration
+	// VaryHeaders 参与缓存键计算的请求头，如 Accept-Language
+	VaryHeaders []string
+	// Tags 缓存条目的标签，用于 InvalidateTag，处理器可以通过 AddTags 追加
+	Tags []string
+	// ShouldStore 判断响应是否需要缓存，默认只缓存状态码为 200 且未设置 ctx.Status 的响应
+	ShouldStore func(ctx *rest.Context) bool
+	// Shared 为 true 时携带 Authorization 或 Cookie 的请求也使用缓存，所有用户共享同一份响应
+	// 只应用于响应与用户无关的路由，并放在认证中间件之后，默认跳过携带凭证的请求
+	Shared bool
+}
+
+// Cache 响应缓存
+type Cache struct {
+	store      Store
+	refreshing sync.Map // 正在后台刷新的键，避免重复刷新
+}
+
+// New 创建响应缓存，store 为 nil 时使用 NewMemoryStore
+func New(store Store) *Cache {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &Cache{
+		store: store,
+	}
+}
+
+// tagsKey 在 Context.Memory 中存储处理器追加标签的键
+type tagsKey struct{}
+
+// AddTags 为当前请求的缓存条目追加标签，如 "user:1"
+func AddTags(ctx *rest.Context, tags ...string) {
+	existing, _ := ctx.Get(tagsKey{})
+	current, _ := existing.([]string)
+	ctx.Set(tagsKey{}, append(slices.Clip(current), tags...))
+}
+
+// Middleware 创建缓存中间件，只对 GET 和 HEAD 请求生效
+//
+// 请求携带 Cache-Control: no-store 时跳过缓存；携带 no-cache 或 max-age=0 时不读取缓存但会更新缓存。
+// 请求携带 Authorization 或 Cookie 时默认跳过缓存，避免把某个用户的响应返回给其他用户，见 Options.Shared。
+// 处理器设置 Cache-Control: no-store 或 private，或响应包含 Set-Cookie 时不会被缓存。
+//
+// 使用示例:
+//
+//	responseCache := respcache.New(nil)
+//	systemGroup.Get("", responseCache.Middleware(respcache.Options{TTL: time.Minute}), rest.Service[GetSystemInfoRequest]())
+func (c *Cache) Middleware(options Options) rest.HandlerFunc {
+	if options.ShouldStore == nil {
+		options.ShouldStore = func(ctx *rest.Context) bool {
+			return ctx.StatusCode == http.StatusOK && ctx.Status == nil
+		}
+	}
+
+	return func(ctx *rest.Context) {
+		if ctx.Method != http.MethodGet && ctx.Method != http.MethodHead {
+			return
+		}
+		requestControl := parseCacheControl(ctx.Request.Header.Get("Cache-Control"))
+		if requestControl.noStore {
+			return
+		}
+		if !options.Shared && hasCredentials(ctx.Request.Header) {
+			return
+		}
+
+		name := options.Name
+		if name == "" {
+			name = ctx.Pattern
+		}
+		key := cacheKey(ctx, name, options.VaryHeaders)
+
+		bypass := requestControl.noCache || (requestControl.hasMaxAge && requestControl.maxAge == 0)
+		if entry, ok := c.store.Get(key); ok && !bypass {
+			now := time.Now()
+			if now.Before(entry.ExpiresAt) {
+				serve(ctx, entry, StateHit, now)
+				ctx.Abort()
+				return
+			}
+			// 先复制上下文再写入旧响应，后台刷新时从当前位置继续执行剩余的处理器
+			c.revalidate(ctx.Copy(), key, name, options)
+			serve(ctx, entry, StateStale, now)
+			ctx.Abort()
+			return
+		}
+
+		before := ctx.Headers.Clone()
+		ctx.Next()
+		c.save(ctx, key, name, options, before)
+		ctx.Headers.Set(StateHeader, StateMiss)
+	}
+}
+
+// InvalidateRoute 删除指定路由名称的所有缓存，返回删除的数量
+func (c *Cache) InvalidateRoute(name string) int {
+	return c.store.DeleteFunc(func(_ string, entry *Entry) bool {
+		return entry.Route == name
+	})
+}
+
+// InvalidateTag 删除带有任意一个指定标签的所有缓存，返回删除的数量
+func (c *Cache) InvalidateTag(tags ...string) int {
+	return c.store.DeleteFunc(func(_ string, entry *Entry) bool {
+		return slices.ContainsFunc(tags, entry.HasTag)
+	})
+}
+
+// revalidate 在后台执行处理器并更新缓存，同一个键同时只会有一个刷新任务
+func (c *Cache) revalidate(ctx *rest.Context, key, name string, options Options) {
+	if _, loaded := c.refreshing.LoadOrStore(key, struct{}{}); loaded {
+		return
+	}
+	before := ctx.Headers.Clone()
+	go func() {
+		defer c.refreshing.Delete(key)
+		defer func() {
+			if err := recover(); err != nil {
+				fmt.Printf("respcache: revalidate %s: %v\n", name, err)
+			}
+		}()
+		ctx.Next()
+		c.save(ctx, key, name, options, before)
+	}()
+}
+
+// save 按照配置和响应的 Cache-Control 保存响应
+func (c *Cache) save(ctx *rest.Context, key, name string, options Options, before http.Header) {
+	// 处理器自行写出的响应和文件响应无法重放
+	if _, isFile := ctx.Result.(*rest.FileResult); isFile || ctx.IsInternalResponseDisabled() || !options.ShouldStore(ctx) {
+		return
+	}
+	control := parseCacheControl(ctx.Headers.Get("Cache-Control"))
+	if control.noStore || control.private {
+		return
+	}
+	// 设置 Cookie 的响应属于特定客户端
+	if _, ok := ctx.Headers["Set-Cookie"]; ok {
+		return
+	}
+
+	ttl := options.TTL
+	switch {
+	case control.hasSharedMaxAge:
+		ttl = control.sharedMaxAge
+	case control.hasMaxAge:
+		ttl = control.maxAge
+	}
+	stale := options.StaleWhileRevalidate
+	if control.hasStaleRevalidate {
+		stale = control.staleRevalidate
+	}
+	if ttl <= 0 && stale <= 0 {
+		return
+	}
+
+	tags := slices.Clone(options.Tags)
+	if extra, ok := ctx.Get(tagsKey{}); ok {
+		tags = append(tags, extra.([]string)...)
+	}
+
+	now := time.Now()
+	c.store.Set(key, &Entry{
+		StatusCode: ctx.StatusCode,
+		Status:     ctx.Status,
+		Headers:    ctx.HeadersChangedSince(before),
+		Result:     ctx.Result,
+
+		Route:      name,
+		Tags:       tags,
+		StoredAt:   now,
+		ExpiresAt:  now.Add(ttl),
+		StaleUntil: now.Add(ttl + stale),
+	})
+}
+
+// serve 将缓存的响应写回上下文
+func serve(ctx *rest.Context, entry *Entry, state string, now time.Time) {
+	for key, values := range entry.Headers {
+		ctx.Headers[key] = slices.Clone(values)
+	}
+	ctx.Headers.Set("Age", strconv.FormatInt(int64(now.Sub(entry.StoredAt)/time.Second), 10))
+	ctx.Headers.Set(StateHeader, state)
+	ctx.SetStatusCode(entry.StatusCode)
+	ctx.SetStatus(entry.Status)
+	ctx.SetResult(entry.Result)
+}
+
+// cacheKey 由路由名称、查询参数和指定的请求头组成缓存键
+func cacheKey(ctx *rest.Context, name string, varyHeaders []string) string {
+	var b strings.Builder
+	b.WriteString(name)
+	// 路由名称相同的不同路径（如 /users/1 和 /users/2）需要区分
+	b.WriteString("\x00")
+	b.WriteString(ctx.Endpoint)
+	b.WriteString("\x00")
+	b.WriteString(ctx.Query.Encode()) // Encode 会按键排序
+	for _, header := range varyHeaders {
+		b.WriteString("\x00")
+		b.WriteString(http.CanonicalHeaderKey(header))
+		b.WriteString("=")
+		b.WriteString(strings.Join(ctx.Request.Header.Values(header), ","))
+	}
+	return b.String()
+}
+
+// hasCredentials 判断请求是否携带了可能用于识别用户的凭证
+func hasCredentials(header http.Header) bool {
+	return header.Get("Authorization") != "" || header.Get("Cookie") != ""
+}
@@ -0,0 +1,97 @@
+package respcache
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/akagiyui/go-together/common/cache"
+)
+
+// Entry 缓存的响应
+type Entry struct {
+	StatusCode int
+	Status     any // 对应 ctx.Status
+	Headers    http.Header
+	Result     any
+
+	Route      string   // 路由名称，用于 InvalidateRoute
+	Tags       []string // 标签，用于 InvalidateTag
+	StoredAt   time.Time
+	ExpiresAt  time.Time // 在此之前为新鲜的响应
+	StaleUntil time.Time // 过期后、在此之前仍可以返回旧的响应并在后台刷新
+}
+
+// HasTag 检查条目是否带有指定标签
+func (e *Entry) HasTag(tag string) bool {
+	return slices.Contains(e.Tags, tag)
+}
+
+// Store 缓存存储，接入 Redis 等外部存储时实现该接口即可
+// Result 和 Status 为任意类型，外部存储需要自行序列化
+type Store interface {
+	// Get 获取缓存，不存在或已超过 StaleUntil 时返回 false
+	Get(key string) (*Entry, bool)
+	// Set 保存缓存
+	Set(key string, entry *Entry)
+	// DeleteFunc 删除所有满足 fn 的缓存，返回删除的数量
+	DeleteFunc(fn func(key string, entry *Entry) bool) int
+}
+
+// MemoryStore 基于 common/cache 的内存存储，仅适用于单实例部署
+type MemoryStore struct {
+	entries *cache.Map[string, *Entry]
+
+	sweepLock sync.Mutex
+	lastSweep time.Time
+}
+
+// NewMemoryStore 创建内存存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries:   cache.NewMap[string, *Entry](),
+		lastSweep: time.Now(),
+	}
+}
+
+// Get 实现 Store 接口
+func (s *MemoryStore) Get(key string) (*Entry, bool) {
+	entry, ok := s.entries.Get(key)
+	if !ok || !time.Now().Before(entry.StaleUntil) {
+		return nil, false
+	}
+	return entry, true
+}
+
+// Set 实现 Store 接口
+func (s *MemoryStore) Set(key string, entry *Entry) {
+	s.entries.Set(key, entry)
+	s.sweep()
+}
+
+// DeleteFunc 实现 Store 接口
+func (s *MemoryStore) DeleteFunc(fn func(key string, entry *Entry) bool) int {
+	return s.entries.DeleteFunc(fn)
+}
+
+// Len 返回缓存条目数量，包括尚未清理的过期条目
+func (s *MemoryStore) Len() int {
+	return s.entries.Len()
+}
+
+// sweep 每分钟最多清理一次已彻底过期的条目
+func (s *MemoryStore) sweep() {
+	now := time.Now()
+	s.sweepLock.Lock()
+	if now.Sub(s.lastSweep) < time.Minute {
+		s.sweepLock.Unlock()
+		return
+	}
+	s.lastSweep = now
+	s.sweepLock.Unlock()
+
+	s.entries.DeleteFunc(func(_ string, entry *Entry) bool {
+		return !now.Before(entry.StaleUntil)
+	})
+}
@@ -0,0 +1,173 @@
+package rest
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileResult 文件响应，作为 ctx.Result 时由 writeResponse 写出
+// Content 实现 io.ReadSeeker 且状态码为 200 时支持 Range、If-Range 及条件请求，否则使用 ctx.StatusCode 按顺序写出全部内容
+// Content 实现 io.Closer 时在写出后关闭，被 OnResponse 钩子替换为其他结果时也会关闭
+type FileResult struct {
+	Content io.Reader
+	Name    string    // 文件名，用于推断 Content-Type 和 Content-Disposition
+	Size    int64     // 内容长度，小于 0 表示未知；Content 可 Seek 时自动获取
+	ModTime time.Time // 修改时间，用于 Last-Modified 和条件请求，零值时不设置
+
+	// Disposition 为 "attachment" 时浏览器下载文件，为 "inline" 时直接展示，为空时不设置 Content-Disposition
+	Disposition string
+}
+
+// File 将本地文件作为响应返回，文件不存在时返回 404
+func (c *Context) File(path string) {
+	file, err := os.Open(path)
+	if err == nil {
+		var info os.FileInfo
+		if info, err = file.Stat(); err == nil && info.IsDir() {
+			err = fs.ErrNotExist
+		}
+		if err == nil {
+			c.SetResult(&FileResult{
+				Content: file,
+				Name:    filepath.Base(path),
+				Size:    info.Size(),
+				ModTime: info.ModTime(),
+			})
+			return
+		}
+		file.Close()
+	}
+
+	if errors.Is(err, fs.ErrNotExist) {
+		c.SetStatusCode(http.StatusNotFound)
+		c.SetResult("404 page not found")
+		return
+	}
+	c.SetStatusCode(http.StatusInternalServerError)
+	c.SetResult(err.Error())
+}
+
+// Attachment 将内容作为附件下载返回，size 小于 0 表示长度未知，modTime 为零值时不设置 Last-Modified
+// content 实现 io.ReadSeeker 时支持断点续传，实现 io.Closer 时在写出后关闭
+func (c *Context) Attachment(content io.Reader, name string, size int64, modTime time.Time) {
+	c.SetResult(&FileResult{
+		Content:     content,
+		Name:        name,
+		Size:        size,
+		ModTime:     modTime,
+		Disposition: "attachment",
+	})
+}
+
+// ContentDisposition 生成 Content-Disposition 响应头
+// 同时提供 ASCII 的 filename 参数和 RFC 5987 编码的 filename* 参数，兼容不支持 filename* 的客户端
+func ContentDisposition(disposition, filename string) string {
+	if filename == "" {
+		return disposition
+	}
+	fallback, needsEncoding := asciiFilename(filename)
+	value := disposition + `; filename="` + fallback + `"`
+	if needsEncoding {
+		value += "; filename*=UTF-8''" + encodeRFC5987(filename)
+	}
+	return value
+}
+
+// writeFile 写出文件响应，可 Seek 的内容交给 http.ServeContent 处理 Range 和条件请求
+func (s *Server) writeFile(w http.ResponseWriter, file *FileResult, ctx *Context) {
+	if closer, ok := file.Content.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	if file.Disposition != "" && w.Header().Get("Content-Disposition") == "" {
+		w.Header().Set("Content-Disposition", ContentDisposition(file.Disposition, file.Name))
+	}
+
+	// 处理器设置了其他状态码（如使用文件作为错误页面）时保留该状态码，不处理 Range 和条件请求
+	if readSeeker, ok := file.Content.(io.ReadSeeker); ok && ctx.OriginalRequest != nil && ctx.StatusCode == http.StatusOK {
+		// ServeContent 根据文件名推断 Content-Type，并处理 Range、If-Range 等请求头
+		http.ServeContent(w, ctx.OriginalRequest, file.Name, file.ModTime, readSeeker)
+		return
+	}
+
+	if contentType := mime.TypeByExtension(filepath.Ext(file.Name)); contentType != "" {
+		setDefaultContentType(w, contentType)
+	} else {
+		setDefaultContentType(w, "application/octet-stream")
+	}
+	if !file.ModTime.IsZero() {
+		w.Header().Set("Last-Modified", file.ModTime.UTC().Format(http.TimeFormat))
+	}
+	if file.Size >= 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(file.Size, 10))
+	}
+	w.Header().Set("Accept-Ranges", "none")
+	w.WriteHeader(ctx.StatusCode)
+	if ctx.Method != http.MethodHead {
+		io.Copy(w, file.Content)
+	}
+}
+
+// closeReplacedFile 文件响应被替换为其他结果时关闭其内容，避免文件句柄泄漏
+func closeReplacedFile(previous, current any) {
+	file, ok := previous.(*FileResult)
+	if !ok || current == previous {
+		return
+	}
+	if closer, ok := file.Content.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+// asciiFilename 生成仅包含 ASCII 可打印字符的文件名，返回是否需要额外的 filename* 参数
+func asciiFilename(filename string) (string, bool) {
+	var builder strings.Builder
+	needsEncoding := false
+	for _, r := range filename {
+		switch {
+		case r > 0x7e || r < 0x20:
+			builder.WriteByte('_')
+			needsEncoding = true
+		case r == '"' || r == '\\':
+			builder.WriteByte('_')
+			needsEncoding = true
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String(), needsEncoding
+}
+
+// encodeRFC5987 按照 RFC 5987 的 attr-char 规则对 UTF-8 字节进行百分号编码
+func encodeRFC5987(value string) string {
+	const hex = "0123456789ABCDEF"
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		b := value[i]
+		if isAttrChar(b) {
+			builder.WriteByte(b)
+			continue
+		}
+		builder.WriteByte('%')
+		builder.WriteByte(hex[b>>4])
+		builder.WriteByte(hex[b&0x0f])
+	}
+	return builder.String()
+}
+
+// isAttrChar 判断字节是否属于 RFC 5987 中无需编码的 attr-char
+func isAttrChar(b byte) bool {
+	switch {
+	case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", b) >= 0
+}
@@ -0,0 +1,63 @@
+package rest
+
+import "net/http"
+
+// StatusWriter 记录实际写出的状态码的 ResponseWriter
+// 包括 OnResponse 钩子修改后的状态码、静态文件返回的 206、304 以及反向代理转发的上游状态码
+type StatusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+// StatusWriter 将 ctx.OriginalWriter 替换为 StatusWriter 并返回，已经替换过时返回同一个 StatusWriter
+// 指标、追踪等需要在响应写出后读取状态码时使用，应在写出响应之前调用
+//
+// 使用示例:
+//
+//	writer := ctx.StatusWriter()
+//	ctx.AfterResponse(func() {
+//	    log.Println(writer.Status())
+//	})
+func (c *Context) StatusWriter() *StatusWriter {
+	if writer, ok := (*c.OriginalWriter).(*StatusWriter); ok {
+		return writer
+	}
+	writer := &StatusWriter{ResponseWriter: *c.OriginalWriter}
+	*c.OriginalWriter = writer
+	return writer
+}
+
+// Status 返回实际写出的状态码，没有写出任何内容时返回 200，与 net/http 的行为一致
+func (w *StatusWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// WriteHeader 记录第一个最终响应的状态码
+func (w *StatusWriter) WriteHeader(statusCode int) {
+	// 1xx 信息响应之后还会有最终响应，101 除外
+	if w.status == 0 && (statusCode >= http.StatusOK || statusCode == http.StatusSwitchingProtocols) {
+		w.status = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write 未调用 WriteHeader 时视为 200
+func (w *StatusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush 支持流式响应
+func (w *StatusWriter) Flush() {
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap 供 http.ResponseController 访问底层的 ResponseWriter
+func (w *StatusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,199 @@
+// Package restclient 使用 rest 的请求结构体构造类型安全的 HTTP 客户端
+//
+// 服务端用于 rest.Service[T] 的请求结构体可以直接作为客户端的请求参数，
+// 客户端根据 path、query、header、cookie、json、form 标签编码请求，并将响应解码为指定类型。
+package restclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/akagiyui/go-together/common/cache"
+	"github.com/akagiyui/go-together/rest"
+)
+
+// Client HTTP 客户端
+type Client struct {
+	BaseURL    string       // 服务地址，如 http://127.0.0.1:8083
+	HTTPClient *http.Client // 为 nil 时使用 http.DefaultClient
+	Header     http.Header  // 每个请求都会携带的请求头，如 Authorization
+
+	routes      *cache.Map[reflect.Type, route]
+	namedRoutes *cache.Map[string, route] // 通过 LoadRoutes 加载的路由，键为处理器名称
+}
+
+// route 请求结构体对应的路由
+type route struct {
+	method  string
+	pattern string
+}
+
+// StatusError 服务端返回了非 2xx 状态码
+type StatusError struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Error 实现 error 接口
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("restclient: unexpected status %d: %s", e.StatusCode, strings.TrimSpace(string(e.Body)))
+}
+
+// New 创建客户端
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:     strings.TrimSuffix(baseURL, "/"),
+		HTTPClient:  nil,
+		Header:      make(http.Header),
+		routes:      cache.NewMap[reflect.Type, route](),
+		namedRoutes: cache.NewMap[string, route](),
+	}
+}
+
+// LoadRoutes 从服务端的路由表加载路由，请求结构体根据处理器名称匹配，无需逐个调用 Register
+// routes 通常来自 rest.Server.Routes()，只有 rest.Service 和 rest.Struct 注册的路由能够匹配，
+// 未指定方法的路由（Any）会被忽略，同一个请求结构体注册在多个路径下时使用第一个
+//
+// 使用示例:
+//
+//	server.Reload()
+//	restclient.LoadRoutes(client, server.Routes())
+//	resp, err := restclient.Call[user.CreateUserRequest, model.GeneralResponse](client, req)
+func LoadRoutes(c *Client, routes []rest.RouteInfo) {
+	for _, info := range routes {
+		if info.Method == "" || info.Handler == "" {
+			continue
+		}
+		c.namedRoutes.GetOrSet(info.Handler, func() route {
+			return route{method: info.Method, pattern: info.Path}
+		})
+	}
+}
+
+// Register 声明请求结构体 Req 对应的路由，pattern 与服务端注册时的完整路径一致
+// 优先于 LoadRoutes 加载的路由，可用于覆盖或补充服务端路由表中没有的路由
+//
+// 使用示例:
+//
+//	restclient.Register[user.CreateUserRequest](client, http.MethodPost, "/v1/users")
+//	restclient.Register[audio.GetOriginAudioDownloadURLRequest](client, http.MethodGet, "/v1/audio/origin/{id}/url")
+func Register[Req any](c *Client, method, pattern string) {
+	t := reflect.TypeOf((*Req)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		panic("restclient.Register: type parameter must be a struct type")
+	}
+	c.routes.Set(t, route{method: method, pattern: pattern})
+}
+
+// Call 发送请求并将响应解码为 Resp
+// Resp 为 string 或 []byte 时直接返回响应体，否则按 JSON 解码
+func Call[Req, Resp any](c *Client, req Req) (Resp, error) {
+	return CallContext[Req, Resp](context.Background(), c, req)
+}
+
+// CallContext 与 Call 相同，但可以通过 ctx 取消请求
+func CallContext[Req, Resp any](ctx context.Context, c *Client, req Req) (Resp, error) {
+	var resp Resp
+
+	t := reflect.TypeOf((*Req)(nil)).Elem()
+	r, ok := c.lookupRoute(t)
+	if !ok {
+		return resp, fmt.Errorf("restclient: no route registered for %s", t)
+	}
+
+	httpRequest, err := c.newRequest(ctx, r, reflect.ValueOf(req))
+	if err != nil {
+		return resp, err
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	httpResponse, err := httpClient.Do(httpRequest)
+	if err != nil {
+		return resp, err
+	}
+	defer httpResponse.Body.Close()
+
+	body, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		return resp, err
+	}
+	if httpResponse.StatusCode < 200 || httpResponse.StatusCode >= 300 {
+		return resp, &StatusError{StatusCode: httpResponse.StatusCode, Header: httpResponse.Header, Body: body}
+	}
+
+	err = decodeResponse(body, &resp)
+	return resp, err
+}
+
+// lookupRoute 查找请求结构体对应的路由，先查找 Register 声明的路由，再按处理器名称查找 LoadRoutes 加载的路由
+func (c *Client) lookupRoute(t reflect.Type) (route, bool) {
+	if r, ok := c.routes.Get(t); ok {
+		return r, true
+	}
+	// 与 rest.Service、rest.Struct 注册的处理器名称一致
+	return c.namedRoutes.Get(t.PkgPath() + "." + t.Name())
+}
+
+// newRequest 根据请求结构体构造 http.Request
+func (c *Client) newRequest(ctx context.Context, r route, value reflect.Value) (*http.Request, error) {
+	encoded, err := encodeRequest(r.pattern, value)
+	if err != nil {
+		return nil, err
+	}
+
+	target := c.BaseURL + encoded.path
+	if len(encoded.query) > 0 {
+		target += "?" + encoded.query.Encode()
+	}
+
+	var body io.Reader
+	if encoded.body != nil {
+		body = bytes.NewReader(encoded.body)
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, r.method, target, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range c.Header {
+		for _, v := range values {
+			httpRequest.Header.Add(key, v)
+		}
+	}
+	for key, values := range encoded.header {
+		httpRequest.Header.Del(key)
+		for _, v := range values {
+			httpRequest.Header.Add(key, v)
+		}
+	}
+	if encoded.contentType != "" {
+		httpRequest.Header.Set("Content-Type", encoded.contentType)
+	}
+	return httpRequest, nil
+}
+
+// decodeResponse 将响应体解码到 out
+func decodeResponse(body []byte, out any) error {
+	switch out := out.(type) {
+	case *string:
+		*out = string(body)
+		return nil
+	case *[]byte:
+		*out = body
+		return nil
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	return json.Unmarshal(body, out)
+}
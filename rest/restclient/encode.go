@@ -0,0 +1,370 @@
+package restclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/akagiyui/go-together/rest"
+)
+
+var (
+	bytesType      = reflect.TypeOf([]byte{})
+	fileHeaderType = reflect.TypeOf(&multipart.FileHeader{})
+)
+
+// encodedRequest 编码后的请求各部分
+type encodedRequest struct {
+	path        string
+	query       url.Values
+	header      http.Header
+	body        []byte
+	contentType string
+}
+
+// formField 表单字段，file 不为 nil 时作为文件上传
+type formField struct {
+	key      string
+	values   []string
+	file     []byte
+	filename string
+}
+
+// requestParts 从结构体中收集到的各类参数
+type requestParts struct {
+	path    map[string]string
+	query   url.Values
+	header  http.Header
+	cookies []string
+	json    map[string]any
+	form    []formField
+}
+
+// encodeRequest 按照字段标签将请求结构体编码为 HTTP 请求的各部分
+func encodeRequest(pattern string, value reflect.Value) (*encodedRequest, error) {
+	parts := &requestParts{
+		path:   make(map[string]string),
+		query:  make(url.Values),
+		header: make(http.Header),
+		json:   make(map[string]any),
+		form:   make([]formField, 0),
+	}
+	if err := parts.collect(value, parts.json); err != nil {
+		return nil, err
+	}
+	if len(parts.cookies) > 0 {
+		parts.header.Set("Cookie", strings.Join(parts.cookies, "; "))
+	}
+
+	path, err := expandPattern(pattern, parts.path)
+	if err != nil {
+		return nil, err
+	}
+	encoded := &encodedRequest{
+		path:   path,
+		query:  parts.query,
+		header: parts.header,
+	}
+
+	// 请求体：JSON 优先，其次是表单
+	switch {
+	case len(parts.json) > 0:
+		encoded.body, err = json.Marshal(parts.json)
+		if err != nil {
+			return nil, err
+		}
+		encoded.contentType = "application/json"
+	case len(parts.form) > 0:
+		encoded.body, encoded.contentType, err = encodeForm(parts.form)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return encoded, nil
+}
+
+// collect 收集结构体字段，未带标签的嵌套结构体会被递归展开
+// jsonTarget 为 JSON 字段写入的位置，匿名嵌入的结构体与外层共用同一层
+func (p *requestParts) collect(value reflect.Value, jsonTarget map[string]any) error {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	structType := value.Type()
+	bound := make(map[int]bool)
+	for _, field := range rest.StructFields(structType) {
+		bound[field.Index] = true
+		fieldValue := value.Field(field.Index)
+		structField := structType.Field(field.Index)
+
+		// 非指针的零值视为未设置，不发送查询参数、请求头和表单字段
+		omitZero := fieldValue.Kind() != reflect.Ptr && fieldValue.IsZero()
+
+		switch field.Source {
+		case "path":
+			values := formatValues(fieldValue)
+			if len(values) > 0 {
+				p.path[field.Key] = values[0]
+			}
+		case "query":
+			if omitZero {
+				continue
+			}
+			for _, v := range formatValues(fieldValue) {
+				p.query.Add(field.Key, v)
+			}
+		case "header":
+			if omitZero {
+				continue
+			}
+			for _, v := range formatValues(fieldValue) {
+				p.header.Add(field.Key, v)
+			}
+		case "cookie":
+			if omitZero {
+				continue
+			}
+			if values := formatValues(fieldValue); len(values) > 0 {
+				cookie := &http.Cookie{Name: field.Key, Value: values[0]}
+				p.cookies = append(p.cookies, cookie.String())
+			}
+		case "json":
+			name, options, _ := strings.Cut(field.Key, ",")
+			if name == "-" && options == "" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			if strings.Contains(","+options+",", ",omitempty,") && fieldValue.IsZero() {
+				continue
+			}
+			if structField.IsExported() {
+				jsonTarget[name] = fieldValue.Interface()
+			}
+		case "form":
+			if omitZero {
+				continue
+			}
+			formField, ok, err := newFormField(field.Key, fieldValue)
+			if err != nil {
+				return err
+			}
+			if ok {
+				p.form = append(p.form, formField)
+			}
+		}
+		// context 和 inject 字段由服务端填充，不需要发送
+	}
+
+	// 递归处理未带标签的嵌套结构体，与服务端参数绑定的规则一致
+	for i := 0; i < structType.NumField(); i++ {
+		structField := structType.Field(i)
+		if bound[i] || !structField.IsExported() {
+			continue
+		}
+		fieldValue := value.Field(i)
+		if fieldValue.Kind() == reflect.Ptr && fieldValue.IsNil() {
+			continue
+		}
+		// 与服务端 QueryBinder 对应的字段自行编码查询参数
+		if encoder, ok := fieldValue.Interface().(rest.QueryEncoder); ok {
+			for key, values := range encoder.EncodeQuery() {
+				p.query[key] = append(p.query[key], values...)
+			}
+			continue
+		}
+
+		fieldType := structField.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() != reflect.Struct {
+			continue
+		}
+
+		target := jsonTarget
+		if !structField.Anonymous {
+			target = make(map[string]any)
+		}
+		if err := p.collect(fieldValue, target); err != nil {
+			return err
+		}
+		if !structField.Anonymous && len(target) > 0 {
+			jsonTarget[structField.Name] = target
+		}
+	}
+	return nil
+}
+
+// newFormField 根据字段类型创建表单字段，[]byte 和 *multipart.FileHeader 作为文件上传
+func newFormField(key string, value reflect.Value) (formField, bool, error) {
+	switch value.Type() {
+	case bytesType:
+		if value.Len() == 0 {
+			return formField{}, false, nil
+		}
+		return formField{key: key, file: value.Bytes(), filename: key}, true, nil
+	case fileHeaderType:
+		if value.IsNil() {
+			return formField{}, false, nil
+		}
+		fileHeader := value.Interface().(*multipart.FileHeader)
+		file, err := fileHeader.Open()
+		if err != nil {
+			return formField{}, false, err
+		}
+		defer file.Close()
+		content, err := io.ReadAll(file)
+		if err != nil {
+			return formField{}, false, err
+		}
+		return formField{key: key, file: content, filename: fileHeader.Filename}, true, nil
+	}
+
+	values := formatValues(value)
+	if len(values) == 0 {
+		return formField{}, false, nil
+	}
+	return formField{key: key, values: values}, true, nil
+}
+
+// encodeForm 编码表单，包含文件时使用 multipart/form-data
+func encodeForm(fields []formField) ([]byte, string, error) {
+	hasFile := false
+	for _, field := range fields {
+		if field.file != nil {
+			hasFile = true
+			break
+		}
+	}
+
+	if !hasFile {
+		values := make(url.Values)
+		for _, field := range fields {
+			values[field.key] = append(values[field.key], field.values...)
+		}
+		return []byte(values.Encode()), "application/x-www-form-urlencoded", nil
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for _, field := range fields {
+		if field.file != nil {
+			part, err := writer.CreateFormFile(field.key, field.filename)
+			if err != nil {
+				return nil, "", err
+			}
+			if _, err := part.Write(field.file); err != nil {
+				return nil, "", err
+			}
+			continue
+		}
+		for _, v := range field.values {
+			if err := writer.WriteField(field.key, v); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), writer.FormDataContentType(), nil
+}
+
+// expandPattern 将路由模式中的通配符替换为路径参数
+// 支持 {name}、{name...} 和 {$}
+func expandPattern(pattern string, params map[string]string) (string, error) {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(pattern, '{')
+		if start == -1 {
+			b.WriteString(pattern)
+			return b.String(), nil
+		}
+		end := strings.IndexByte(pattern[start:], '}')
+		if end == -1 {
+			return "", fmt.Errorf("restclient: invalid pattern %q", pattern)
+		}
+		end += start
+
+		b.WriteString(pattern[:start])
+		name := pattern[start+1 : end]
+		pattern = pattern[end+1:]
+
+		if name == "$" {
+			continue
+		}
+		multi := strings.HasSuffix(name, "...")
+		name = strings.TrimSuffix(name, "...")
+
+		value, ok := params[name]
+		if !ok {
+			if multi {
+				continue
+			}
+			return "", fmt.Errorf("restclient: missing path parameter %q", name)
+		}
+		if multi {
+			// 多段通配符保留 /，逐段转义
+			segments := strings.Split(value, "/")
+			for i, segment := range segments {
+				segments[i] = url.PathEscape(segment)
+			}
+			b.WriteString(strings.Join(segments, "/"))
+		} else {
+			b.WriteString(url.PathEscape(value))
+		}
+	}
+}
+
+// formatValues 将字段值格式化为字符串列表，空指针返回 nil
+func formatValues(value reflect.Value) []string {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.Slice && value.Type() != bytesType {
+		values := make([]string, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			values = append(values, formatValues(value.Index(i))...)
+		}
+		return values
+	}
+	if s, ok := formatScalar(value); ok {
+		return []string{s}
+	}
+	return nil
+}
+
+// formatScalar 格式化标量值，与服务端 setScalarValue 支持的类型一致
+func formatScalar(value reflect.Value) (string, bool) {
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 64), true
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), true
+	}
+	return "", false
+}
@@ -0,0 +1,133 @@
+package resttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/akagiyui/go-together/rest/record"
+)
+
+// maxValueLength 差异描述中单个值的最大长度
+const maxValueLength = 200
+
+// compareBody 比较录制和重放得到的响应体，两者都是 JSON 时逐字段比较
+func compareBody(recorded, replayed []byte, ignored map[string]bool) []string {
+	recordedValue, recordedOK := decodeJSON(recorded)
+	replayedValue, replayedOK := decodeJSON(replayed)
+	if recordedOK && replayedOK {
+		var changes []string
+		compareValue("", recordedValue, replayedValue, ignored, &changes)
+		return changes
+	}
+	if bytes.Equal(recorded, replayed) {
+		return nil
+	}
+	return []string{fmt.Sprintf("body: %s -> %s", shorten(strconv.Quote(string(recorded))), shorten(strconv.Quote(string(replayed))))}
+}
+
+// compareValue 递归比较 JSON 值，将差异以 "路径: 录制值 -> 重放值" 的形式追加到 changes
+func compareValue(path string, recorded, replayed any, ignored map[string]bool, changes *[]string) {
+	if recorded == record.Redacted {
+		return
+	}
+	switch recorded := recorded.(type) {
+	case map[string]any:
+		if replayed, ok := replayed.(map[string]any); ok {
+			keys := make([]string, 0, len(recorded)+len(replayed))
+			for key := range recorded {
+				keys = append(keys, key)
+			}
+			for key := range replayed {
+				if _, exists := recorded[key]; !exists {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				childPath := joinPath(path, key)
+				if ignored[key] || ignored[childPath] {
+					continue
+				}
+				recordedChild, recordedOK := recorded[key]
+				replayedChild, replayedOK := replayed[key]
+				switch {
+				case !replayedOK:
+					*changes = append(*changes, fmt.Sprintf("%s: %s -> missing", childPath, format(recordedChild)))
+				case !recordedOK:
+					*changes = append(*changes, fmt.Sprintf("%s: missing -> %s", childPath, format(replayedChild)))
+				default:
+					compareValue(childPath, recordedChild, replayedChild, ignored, changes)
+				}
+			}
+			return
+		}
+	case []any:
+		if replayed, ok := replayed.([]any); ok {
+			if len(recorded) != len(replayed) {
+				*changes = append(*changes, fmt.Sprintf("%s: length %d -> %d", displayPath(path), len(recorded), len(replayed)))
+			}
+			for i := range min(len(recorded), len(replayed)) {
+				childPath := path + "[" + strconv.Itoa(i) + "]"
+				if !ignored[childPath] {
+					compareValue(childPath, recorded[i], replayed[i], ignored, changes)
+				}
+			}
+			return
+		}
+	default:
+		if recorded == replayed {
+			return
+		}
+	}
+	*changes = append(*changes, fmt.Sprintf("%s: %s -> %s", displayPath(path), format(recorded), format(replayed)))
+}
+
+// decodeJSON 解析 JSON，数字保留为 json.Number 以便精确比较
+func decodeJSON(data []byte) (any, bool) {
+	if len(data) == 0 || !json.Valid(data) {
+		return nil, false
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// joinPath 拼接字段路径
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// displayPath 根路径显示为 body
+func displayPath(path string) string {
+	if path == "" {
+		return "body"
+	}
+	return path
+}
+
+// format 将 JSON 值格式化为差异描述中的文本
+func format(value any) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return shorten(string(b))
+}
+
+// shorten 截断过长的值
+func shorten(s string) string {
+	if len(s) <= maxValueLength {
+		return s
+	}
+	return s[:maxValueLength] + "..."
+}
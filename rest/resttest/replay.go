@@ -0,0 +1,166 @@
+// Package resttest 提供测试和排查问题用的工具
+package resttest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+
+	"github.com/akagiyui/go-together/rest/record"
+)
+
+// maxLineSize 录制文件单行的最大长度
+const maxLineSize = 16 << 20
+
+// ReplayOptions 重放配置
+type ReplayOptions struct {
+	// Filter 返回 false 时跳过该记录，如只重放某个路径
+	Filter func(entry *record.Entry) bool
+	// Rewrite 发送前修改请求，用于替换被脱敏的 Authorization 等请求头和字段
+	Rewrite func(r *http.Request)
+
+	// IgnoreFields 比较 JSON 响应体时忽略的字段，可以是字段名（如 "created_at"）或完整路径（如 "data.items[0].id"）
+	IgnoreFields []string
+	// CompareHeaders 需要比较的响应头，默认只比较 Content-Type
+	CompareHeaders []string
+}
+
+// Diff 一条记录重放后与录制结果的差异
+type Diff struct {
+	Line   int           // 记录在录制文件中的行号，从 1 开始
+	Entry  *record.Entry // 录制的记录
+	Status int           // 重放得到的状态码，记录无法重放时为 0
+	Body   []byte        // 重放得到的响应体
+
+	// Changes 差异描述，如 `status: 200 -> 500`、`data.name: "a" -> "b"`
+	Changes []string
+}
+
+// String 返回适合输出到日志的差异描述
+func (d Diff) String() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "line %d: %s %s", d.Line, d.Entry.Method, d.Entry.Path)
+	for _, change := range d.Changes {
+		builder.WriteString("\n  ")
+		builder.WriteString(change)
+	}
+	return builder.String()
+}
+
+// Replay 将录制的请求依次发送给 handler，返回响应与录制结果不一致的记录
+//
+// 录制时被脱敏的值（record.Redacted）不参与比较；请求体被截断的记录无法重放，会作为差异返回。
+// handler 通常是 *rest.Server，请求直接调用 ServeHTTP，不经过网络。
+//
+// 使用示例:
+//
+//	diffs, err := resttest.ReplayFile(server, "captures/traffic.jsonl", resttest.ReplayOptions{
+//	    Rewrite: func(r *http.Request) {
+//	        if r.Header.Get("Authorization") == record.Redacted {
+//	            r.Header.Set("Authorization", "Bearer "+localAPIKey)
+//	        }
+//	    },
+//	    IgnoreFields: []string{"created_at", "updated_at"},
+//	})
+//	for _, diff := range diffs {
+//	    fmt.Println(diff)
+//	}
+func Replay(handler http.Handler, capture io.Reader, options ...ReplayOptions) ([]Diff, error) {
+	var opts ReplayOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if len(opts.CompareHeaders) == 0 {
+		opts.CompareHeaders = []string{"Content-Type"}
+	}
+	ignored := make(map[string]bool, len(opts.IgnoreFields))
+	for _, field := range opts.IgnoreFields {
+		ignored[field] = true
+	}
+
+	var diffs []Diff
+	scanner := bufio.NewScanner(capture)
+	scanner.Buffer(make([]byte, 64<<10), maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		entry := new(record.Entry)
+		if err := json.Unmarshal(scanner.Bytes(), entry); err != nil {
+			return diffs, fmt.Errorf("resttest: line %d: %w", line, err)
+		}
+		if opts.Filter != nil && !opts.Filter(entry) {
+			continue
+		}
+		if diff := replayEntry(handler, entry, opts, ignored); len(diff.Changes) > 0 {
+			diff.Line = line
+			diffs = append(diffs, diff)
+		}
+	}
+	return diffs, scanner.Err()
+}
+
+// ReplayFile 重放录制文件，参见 Replay
+func ReplayFile(handler http.Handler, path string, options ...ReplayOptions) ([]Diff, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Replay(handler, file, options...)
+}
+
+// replayEntry 重放单条记录并比较响应
+func replayEntry(handler http.Handler, entry *record.Entry, options ReplayOptions, ignored map[string]bool) Diff {
+	diff := Diff{Entry: entry}
+	body, err := entry.RequestBody()
+	if err != nil {
+		diff.Changes = append(diff.Changes, fmt.Sprintf("invalid request body: %v", err))
+		return diff
+	}
+	if entry.BodyTruncated {
+		diff.Changes = append(diff.Changes, "request body was truncated when recording, not replayed")
+		return diff
+	}
+
+	r := httptest.NewRequest(entry.Method, entry.Path, bytes.NewReader(body))
+	for key, values := range entry.Header {
+		r.Header[key] = append([]string(nil), values...)
+	}
+	r.Header.Del("Content-Length")
+	if options.Rewrite != nil {
+		options.Rewrite(r)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, r)
+	diff.Status, diff.Body = recorder.Code, recorder.Body.Bytes()
+
+	if recorder.Code != entry.Status {
+		diff.Changes = append(diff.Changes, fmt.Sprintf("status: %d -> %d", entry.Status, recorder.Code))
+	}
+	for _, key := range options.CompareHeaders {
+		recorded, replayed := entry.ResponseHeader.Get(key), recorder.Header().Get(key)
+		if recorded != replayed && recorded != record.Redacted {
+			diff.Changes = append(diff.Changes, fmt.Sprintf("header %s: %q -> %q", http.CanonicalHeaderKey(key), recorded, replayed))
+		}
+	}
+
+	if entry.ResultTruncated {
+		// 响应体不完整，只比较状态码和响应头
+		return diff
+	}
+	recordedBody, err := entry.ResponseBody()
+	if err != nil {
+		diff.Changes = append(diff.Changes, fmt.Sprintf("invalid recorded result: %v", err))
+		return diff
+	}
+	diff.Changes = append(diff.Changes, compareBody(recordedBody, diff.Body, ignored)...)
+	return diff
+}
@@ -0,0 +1,245 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// BatchOptions 批量请求配置
+type BatchOptions struct {
+	MaxRequests int   // 单次批量请求包含的子请求数量上限，默认 20
+	Concurrency int   // 同时执行的子请求数量，默认 4
+	MaxBodySize int64 // 批量请求体的大小上限，默认 1 MiB
+}
+
+// BatchRequest 批量请求中的子请求
+type BatchRequest struct {
+	Method  string            `json:"method"`  // 默认为 GET
+	Path    string            `json:"path"`    // 包含查询参数，如 /v1/audio?page=1
+	Headers map[string]string `json:"headers"` // 覆盖批量请求本身的同名请求头
+	Body    json.RawMessage   `json:"body"`    // JSON 请求体，未设置 Content-Type 时使用 application/json
+}
+
+// BatchResponse 子请求的响应
+type BatchResponse struct {
+	Status  int             `json:"status"`
+	Headers http.Header     `json:"headers,omitempty"`
+	Body    json.RawMessage `json:"body,omitempty"` // JSON 响应原样返回，其他响应编码为 JSON 字符串
+}
+
+// batchKey 子请求 context 中的标记，禁止嵌套批量请求
+type batchKey struct{}
+
+// Batch 批量请求处理器，将 JSON 数组中的子请求通过同一个路由表和中间件分发，并按顺序返回所有结果
+//
+// 子请求继承批量请求的请求头（如 Authorization），可以通过 headers 覆盖。
+// 子请求不能再次调用批量请求接口。
+//
+// 使用示例:
+//
+//	server.Post("/batch", rest.Batch(rest.BatchOptions{MaxRequests: 20}))
+//
+//	// POST /batch
+//	// [{"method": "GET", "path": "/v1/users/me"}, {"method": "GET", "path": "/v1/system"}]
+func Batch(options BatchOptions) HandlerFunc {
+	if options.MaxRequests <= 0 {
+		options.MaxRequests = 20
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = 4
+	}
+	if options.MaxBodySize <= 0 {
+		options.MaxBodySize = 1 << 20
+	}
+
+	handler := func(ctx *Context) {
+		if ctx.Context().Value(batchKey{}) != nil {
+			batchError(ctx, http.StatusBadRequest, "Bad Request: nested batch requests are not allowed")
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(ctx.OriginalRequest.Body, options.MaxBodySize+1))
+		if err != nil {
+			batchError(ctx, http.StatusBadRequest, "Bad Request: "+err.Error())
+			return
+		}
+		if int64(len(body)) > options.MaxBodySize {
+			batchError(ctx, http.StatusRequestEntityTooLarge, "Request Entity Too Large")
+			return
+		}
+		var requests []BatchRequest
+		if err := json.Unmarshal(body, &requests); err != nil {
+			batchError(ctx, http.StatusBadRequest, "Bad Request: "+err.Error())
+			return
+		}
+		if len(requests) > options.MaxRequests {
+			batchError(ctx, http.StatusRequestEntityTooLarge, "Request Entity Too Large: too many requests in batch")
+			return
+		}
+		for _, request := range requests {
+			if !strings.HasPrefix(request.Path, "/") {
+				batchError(ctx, http.StatusBadRequest, "Bad Request: path must start with /")
+				return
+			}
+		}
+
+		responses := make([]BatchResponse, len(requests))
+		semaphore := make(chan struct{}, options.Concurrency)
+		var wg sync.WaitGroup
+		for i, request := range requests {
+			wg.Add(1)
+			semaphore <- struct{}{}
+			go func() {
+				defer func() {
+					<-semaphore
+					wg.Done()
+				}()
+				responses[i] = dispatchBatchRequest(ctx, request)
+			}()
+		}
+		wg.Wait()
+
+		ctx.SetResult(responses)
+	}
+	registerHandlerName(handler, "rest.Batch")
+	return handler
+}
+
+// batchError 设置批量请求本身的错误响应
+func batchError(ctx *Context, statusCode int, message string) {
+	ctx.SetStatusCode(statusCode)
+	ctx.SetResult(message)
+	ctx.Abort()
+}
+
+// dispatchBatchRequest 通过服务器的路由表执行子请求
+func dispatchBatchRequest(ctx *Context, request BatchRequest) BatchResponse {
+	method := strings.ToUpper(request.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	var body io.Reader = http.NoBody
+	if len(request.Body) > 0 && string(request.Body) != "null" {
+		body = bytes.NewReader(request.Body)
+	}
+
+	subContext := context.WithValue(ctx.Context(), batchKey{}, true)
+	r, err := http.NewRequestWithContext(subContext, method, request.Path, body)
+	if err != nil {
+		return BatchResponse{Status: http.StatusBadRequest, Body: batchMessage(err.Error())}
+	}
+	r.RequestURI = request.Path
+	r.Host = ctx.Host
+	r.RemoteAddr = ctx.RemoteAddr
+	r.Proto, r.ProtoMajor, r.ProtoMinor = ctx.OriginalRequest.Proto, ctx.OriginalRequest.ProtoMajor, ctx.OriginalRequest.ProtoMinor
+
+	// 继承批量请求的请求头，与请求体相关的、逐跳的以及幂等键除外
+	// 幂等键属于批量请求本身，子请求继承后会被当作同一个键的不同请求
+	connectionHeaders := connectionTokens(ctx.Request.Header)
+	for key, values := range ctx.Request.Header {
+		key = http.CanonicalHeaderKey(key)
+		if batchSkippedHeaders[key] || connectionHeaders[key] {
+			continue
+		}
+		r.Header[key] = append([]string(nil), values...)
+	}
+	for key, value := range request.Headers {
+		r.Header.Set(key, value)
+	}
+	if body != http.NoBody && r.Header.Get("Content-Type") == "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+
+	recorder := &batchRecorder{header: make(http.Header), status: 0}
+	ctx.Server.ServeHTTP(recorder, r)
+	return recorder.response()
+}
+
+// batchSkippedHeaders 子请求不继承的请求头
+var batchSkippedHeaders = map[string]bool{
+	"Content-Type":        true,
+	"Content-Length":      true,
+	"Content-Encoding":    true,
+	"Idempotency-Key":     true,
+	"Connection":          true,
+	"Proxy-Connection":    true,
+	"Keep-Alive":          true,
+	"Proxy-Authenticate":  true,
+	"Proxy-Authorization": true,
+	"Te":                  true,
+	"Trailer":             true,
+	"Transfer-Encoding":   true,
+	"Upgrade":             true,
+}
+
+// connectionTokens 返回 Connection 头中声明的逐跳请求头
+func connectionTokens(header http.Header) map[string]bool {
+	var tokens map[string]bool
+	for _, value := range header.Values("Connection") {
+		for _, token := range strings.Split(value, ",") {
+			if token = strings.TrimSpace(token); token != "" {
+				if tokens == nil {
+					tokens = make(map[string]bool)
+				}
+				tokens[http.CanonicalHeaderKey(token)] = true
+			}
+		}
+	}
+	return tokens
+}
+
+// batchRecorder 记录子请求响应的 http.ResponseWriter
+type batchRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *batchRecorder) Header() http.Header { return w.header }
+
+func (w *batchRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.body.Write(b)
+}
+
+func (w *batchRecorder) WriteHeader(statusCode int) {
+	if w.status == 0 {
+		w.status = statusCode
+	}
+}
+
+func (w *batchRecorder) Flush() {}
+
+// response 将记录的响应转换为 BatchResponse
+func (w *batchRecorder) response() BatchResponse {
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	response := BatchResponse{Status: status, Headers: w.header}
+	if w.body.Len() == 0 {
+		return response
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(w.header.Get("Content-Type"))
+	if mediaType == "application/json" && json.Valid(w.body.Bytes()) {
+		response.Body = w.body.Bytes()
+	} else {
+		response.Body = batchMessage(w.body.String())
+	}
+	return response
+}
+
+// batchMessage 将文本编码为 JSON 字符串
+func batchMessage(message string) json.RawMessage {
+	b, _ := json.Marshal(message)
+	return b
+}
@@ -2,6 +2,7 @@ package rest
 
 // Validator 接口用于在参数绑定后、业务处理前进行数据校验
 // 实现此接口的 handler 会在 Handle 方法调用前自动执行 Validate 方法
+// 字段上的 validate 标签会先于 Validate 方法自动校验，通常只需在此编写跨字段的校验逻辑
 type Validator interface {
 	Validate() error
 }
@@ -12,6 +13,8 @@ type HandlerFactory struct {
 	Method       string
 	RunnerChain  []HandlerFunc
 	HandlerNames []string // 存储每个 handler 的名称，用于调试输出
+
+	groups []*RouteGroup // 路由所属的路由组，由外到内，用于判断路由是否被禁用
 }
 
 // Handle 注册处理器到指定路径和方法
@@ -34,5 +37,6 @@ func (g *RouteGroup) Handle(path string, method string, handlers ...HandlerFunc)
 	for i, f := range handlers {
 		factory.HandlerNames[i] = funcName(f)
 	}
+	defer g.lockTree()()
 	g.Factories = append(g.Factories, factory)
 }
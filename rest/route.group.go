@@ -1,5 +1,10 @@
 package rest
 
+import (
+	"sync/atomic"
+	"time"
+)
+
 // RouteGroup 路由组
 type RouteGroup struct {
 	Factories      []HandlerFactory
@@ -8,7 +13,10 @@ type RouteGroup struct {
 	PreRunnerChain []HandlerFunc
 	PreRunnerNames []string // 存储前置 handler 的名称，用于调试输出
 
-	server *Server
+	server      *Server
+	versionings []*Versioning // 挂载在当前组下的版本化路由
+	timeout     time.Duration // 当前组的超时时间，为 0 时继承上级
+	disabled    atomic.Bool   // 当前组是否被禁用
 }
 
 // NewRouteGroup 创建一个新的路由组
@@ -32,15 +40,27 @@ func NewRouteGroup(server *Server, basePath string, preRunnerChain ...HandlerFun
 // Group 创建子组
 func (g *RouteGroup) Group(basePath string, preRunnerChain ...HandlerFunc) *RouteGroup {
 	childGroup := NewRouteGroup(g.server, basePath, preRunnerChain...)
+	defer g.lockTree()()
 	g.ChildGroups = append(g.ChildGroups, &childGroup)
 	return &childGroup
 }
 
 // Use 为当前组添加前置处理器
 func (g *RouteGroup) Use(handlers ...HandlerFunc) {
+	defer g.lockTree()()
 	g.PreRunnerChain = append(g.PreRunnerChain, handlers...)
 	// 获取函数名称
 	for _, f := range handlers {
 		g.PreRunnerNames = append(g.PreRunnerNames, funcName(f))
 	}
 }
+
+// lockTree 锁定所属服务器的路由树，返回解锁函数，使修改路由树与 Reload 互斥
+// 不属于任何服务器的路由组不加锁
+func (g *RouteGroup) lockTree() func() {
+	if g.server == nil {
+		return func() {}
+	}
+	g.server.reloadLock.Lock()
+	return g.server.reloadLock.Unlock
+}
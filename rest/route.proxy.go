@@ -0,0 +1,203 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// proxyPathParam 代理路由中剩余路径的参数名
+const proxyPathParam = "proxyPath"
+
+// ProxyOptions 反向代理配置
+type ProxyOptions struct {
+	// KeepPrefix 为 true 时转发完整路径，默认去掉路由前缀，如 /proxy/api/users 转发为 /users
+	KeepPrefix bool
+	// PreserveHost 为 true 时保留客户端请求的 Host，默认使用目标地址的 Host
+	PreserveHost bool
+	// XForwarded 为 true 时设置 X-Forwarded-For、X-Forwarded-Host 和 X-Forwarded-Proto
+	// 默认不向上游暴露客户端信息，客户端发送的 X-Forwarded-* 请求头总是会被移除
+	XForwarded bool
+
+	// SetHeaders 转发前设置的请求头
+	SetHeaders map[string]string
+	// RemoveHeaders 转发前删除的请求头，以 * 结尾时按前缀匹配，如 "X-Real-*"
+	RemoveHeaders []string
+	// SetResponseHeaders 返回前设置的响应头
+	SetResponseHeaders map[string]string
+	// RemoveResponseHeaders 返回前删除的上游响应头，以 * 结尾时按前缀匹配，如 "Access-Control-*"
+	RemoveResponseHeaders []string
+	// StripCookies 为 true 时不转发请求的 Cookie，也不返回上游的 Set-Cookie
+	StripCookies bool
+
+	// Timeout 单次转发的超时时间，包括读取上游响应体，为 0 时不限制，超时返回 504
+	Timeout time.Duration
+	// FlushInterval 写出响应体时的刷新间隔，为负数时每次写入后立即刷新，流式响应会自动立即刷新
+	FlushInterval time.Duration
+	// Transport 转发请求使用的 RoundTripper，默认为 http.DefaultTransport
+	Transport http.RoundTripper
+}
+
+// Proxy 将 prefix 下的所有请求转发到 target，请求会经过路由组的中间件
+// 中间件设置的响应头会覆盖上游返回的同名响应头；上游不可达时返回 502，超时返回 504
+//
+// 使用示例:
+//
+//	server.Proxy("/proxy/rain/api", "https://api.v2.rainyun.com", rest.ProxyOptions{
+//	    StripCookies:          true,
+//	    SetHeaders:            map[string]string{"Referer": "https://app.rainyun.com/"},
+//	    RemoveResponseHeaders: []string{"Access-Control-*"},
+//	    Timeout:               30 * time.Second,
+//	})
+func (g *RouteGroup) Proxy(prefix string, target string, options ...ProxyOptions) {
+	targetURL, err := url.Parse(target)
+	if err != nil || targetURL.Scheme == "" || targetURL.Host == "" {
+		panic(fmt.Sprintf("rest.Proxy: invalid target %q", target))
+	}
+	var opts ProxyOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.Transport == nil {
+		opts.Transport = http.DefaultTransport
+	}
+
+	prefix = strings.TrimSuffix(prefix, "/")
+	proxy := newReverseProxy(targetURL, opts)
+	handler := func(ctx *Context) {
+		serveProxy(ctx, proxy, opts)
+	}
+	registerHandlerName(handler, "rest.Proxy")
+	g.Any(prefix+"/{"+proxyPathParam+"...}", handler)
+}
+
+// proxyContextKey 在转发请求的 context 中保存当前请求的 Context
+type proxyContextKey struct{}
+
+// proxyContext 从转发请求的 context 中获取当前请求的 Context
+func proxyContext(r *http.Request) *Context {
+	return r.Context().Value(proxyContextKey{}).(*Context)
+}
+
+// serveProxy 转发单个请求，成功时直接写出上游响应，失败时交给框架写出错误信息
+func serveProxy(ctx *Context, proxy *httputil.ReverseProxy, options ProxyOptions) {
+	if ctx.OriginalRequest == nil {
+		ctx.SetStatusCode(http.StatusBadGateway)
+		ctx.SetResult("Bad Gateway")
+		return
+	}
+
+	requestContext := ctx.Context()
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		requestContext, cancel = context.WithTimeout(requestContext, options.Timeout)
+		defer cancel()
+	}
+	requestContext = context.WithValue(requestContext, proxyContextKey{}, ctx)
+	proxy.ServeHTTP(*ctx.OriginalWriter, ctx.OriginalRequest.WithContext(requestContext))
+}
+
+// newReverseProxy 创建转发到 target 的 ReverseProxy，每个 Proxy 路由只创建一次
+// 请求相关的数据通过转发请求的 context 获取
+func newReverseProxy(target *url.URL, options ProxyOptions) *httputil.ReverseProxy {
+	return &httputil.ReverseProxy{
+		Transport:     options.Transport,
+		FlushInterval: options.FlushInterval,
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			if !options.KeepPrefix {
+				// 路由前缀，即匹配到的路由路径去掉剩余路径参数，可能包含其他路径参数
+				mountPath := strings.TrimSuffix(proxyContext(pr.In).Pattern, "/{"+proxyPathParam+"...}")
+				rawPath := stripSegments(pr.In.URL.EscapedPath(), strings.Count(mountPath, "/"))
+				if path, err := url.PathUnescape(rawPath); err == nil {
+					pr.Out.URL.Path, pr.Out.URL.RawPath = path, rawPath
+				}
+			}
+			pr.SetURL(target)
+			if options.PreserveHost {
+				pr.Out.Host = pr.In.Host
+			}
+			if options.XForwarded {
+				pr.SetXForwarded()
+			}
+
+			if options.StripCookies {
+				pr.Out.Header.Del("Cookie")
+			}
+			removeHeaders(pr.Out.Header, options.RemoveHeaders)
+			for key, value := range options.SetHeaders {
+				pr.Out.Header.Set(key, value)
+			}
+		},
+		ModifyResponse: func(res *http.Response) error {
+			ctx := proxyContext(res.Request)
+			if options.StripCookies {
+				res.Header.Del("Set-Cookie")
+			}
+			removeHeaders(res.Header, options.RemoveResponseHeaders)
+			for key, value := range options.SetResponseHeaders {
+				res.Header.Set(key, value)
+			}
+			// 中间件设置的响应头优先
+			for key := range ctx.Headers {
+				res.Header.Del(key)
+			}
+
+			// 接下来由 ReverseProxy 写出响应，记录状态码供外层中间件使用
+			ctx.SetStatusCode(res.StatusCode)
+			ctx.DisableInternalResponse()
+			ctx.writeHeaders()
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			ctx := proxyContext(r)
+			if ctx.IsInternalResponseDisabled() {
+				// 已经开始写出上游响应，只能中断
+				return
+			}
+			if ctx.Server != nil && ctx.Server.Debug {
+				fmt.Printf("rest.Proxy: %s %s: %v\n", r.Method, r.URL, err)
+			}
+			if errors.Is(err, context.DeadlineExceeded) {
+				ctx.SetStatusCode(http.StatusGatewayTimeout)
+				ctx.SetResult("Gateway Timeout: upstream timed out")
+				return
+			}
+			ctx.SetStatusCode(http.StatusBadGateway)
+			ctx.SetResult("Bad Gateway")
+		},
+	}
+}
+
+// removeHeaders 删除指定的头部，以 * 结尾的名称按前缀匹配
+func removeHeaders(header http.Header, names []string) {
+	for _, name := range names {
+		prefix, isPrefix := strings.CutSuffix(name, "*")
+		if !isPrefix {
+			header.Del(name)
+			continue
+		}
+		prefix = http.CanonicalHeaderKey(prefix)
+		for key := range header {
+			if strings.HasPrefix(key, prefix) {
+				header.Del(key)
+			}
+		}
+	}
+}
+
+// stripSegments 去掉转义后路径的前 n 个路径段，路由按转义后的路径段匹配，因此 %2F 不会被拆开
+func stripSegments(rawPath string, n int) string {
+	for i := 0; i < n; i++ {
+		next := strings.IndexByte(rawPath[1:], '/')
+		if next < 0 {
+			return "/"
+		}
+		rawPath = rawPath[next+1:]
+	}
+	return rawPath
+}
@@ -0,0 +1,310 @@
+package rest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akagiyui/go-together/common/cache"
+)
+
+// StaticOptions 静态文件服务配置
+type StaticOptions struct {
+	// Index 目录索引文件名，默认为 index.html
+	Index string
+	// DisableIndex 为 true 时，请求目录不会返回索引文件
+	DisableIndex bool
+	// ListDirectory 为 true 时，目录没有索引文件时返回文件列表
+	ListDirectory bool
+	// SPA 为 true 时，未找到的页面请求会回退到根目录的索引文件，用于前端路由
+	// 仅对没有扩展名且 Accept 包含 text/html 的请求生效，静态资源缺失时仍返回 404
+	SPA bool
+	// Precompressed 为 true 时，优先返回同名的 .br 或 .gz 预压缩文件
+	Precompressed bool
+	// MaxAge 设置 Cache-Control 的 max-age，为 0 时不设置
+	MaxAge time.Duration
+}
+
+// precompressedEncodings 预压缩文件的编码及扩展名，按优先级排列
+var precompressedEncodings = []struct {
+	encoding  string
+	extension string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// staticServer 静态文件处理器
+type staticServer struct {
+	fsys    fs.FS
+	options StaticOptions
+
+	// 没有修改时间的文件（如 embed.FS）使用内容摘要作为 ETag，按路径缓存
+	etags *cache.Map[string, string]
+}
+
+// Static 在 prefix 下提供 fsys 中的静态文件，支持 Range、ETag、Last-Modified 和预压缩文件
+//
+// 使用示例:
+//
+//	//go:embed dist
+//	var dist embed.FS
+//
+//	sub, _ := fs.Sub(dist, "dist")
+//	server.Static("/", sub, rest.StaticOptions{SPA: true, Precompressed: true})
+func (g *RouteGroup) Static(prefix string, fsys fs.FS, options ...StaticOptions) {
+	var opts StaticOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.Index == "" {
+		opts.Index = "index.html"
+	}
+
+	prefix = strings.TrimSuffix(prefix, "/")
+	server := &staticServer{
+		fsys:    fsys,
+		options: opts,
+		etags:   cache.NewMap[string, string](),
+	}
+
+	handler := server.serve
+	registerHandlerName(handler, "rest.Static")
+	// GET 路由同时匹配 HEAD 请求
+	g.Get(prefix+"/{path...}", handler)
+}
+
+// serve 处理单个静态文件请求
+func (s *staticServer) serve(ctx *Context) {
+	name := path.Clean("/" + ctx.PathParams["path"])[1:]
+	if name == "" {
+		name = "."
+	}
+
+	file, info, err := s.open(name)
+	if err == nil && info.IsDir() {
+		file.Close()
+		name, file, info, err = s.openDirectory(ctx, name)
+		if file == nil && err == nil {
+			// 已完成重定向或目录列表
+			return
+		}
+	}
+
+	if err != nil {
+		if s.options.SPA && s.acceptsFallback(ctx, name) {
+			file, info, err = s.open(s.options.Index)
+			name = s.options.Index
+		}
+		if err != nil {
+			s.notFound(ctx)
+			return
+		}
+	}
+	defer file.Close()
+
+	s.serveFile(ctx, name, file, info)
+}
+
+// open 打开文件并获取文件信息
+func (s *staticServer) open(name string) (fs.File, fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, nil, fs.ErrInvalid
+	}
+	file, err := s.fsys.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return file, info, nil
+}
+
+// openDirectory 处理目录请求，返回索引文件；完成重定向或输出目录列表时返回的文件为 nil
+func (s *staticServer) openDirectory(ctx *Context, name string) (string, fs.File, fs.FileInfo, error) {
+	// 目录必须以 / 结尾，保证页面中的相对路径正确
+	if !strings.HasSuffix(ctx.Endpoint, "/") {
+		target := ctx.Endpoint + "/"
+		if ctx.URL.RawQuery != "" {
+			target += "?" + ctx.URL.RawQuery
+		}
+		ctx.DisableInternalResponse()
+		ctx.writeHeaders()
+		http.Redirect(*ctx.OriginalWriter, ctx.OriginalRequest, target, http.StatusMovedPermanently)
+		return name, nil, nil, nil
+	}
+
+	if !s.options.DisableIndex {
+		indexName := path.Join(name, s.options.Index)
+		file, info, err := s.open(indexName)
+		if err == nil && !info.IsDir() {
+			return indexName, file, info, nil
+		}
+	}
+
+	if s.options.ListDirectory {
+		s.listDirectory(ctx, name)
+		return name, nil, nil, nil
+	}
+	return name, nil, nil, fs.ErrNotExist
+}
+
+// listDirectory 输出简单的目录列表页面
+func (s *staticServer) listDirectory(ctx *Context, name string) {
+	entries, err := fs.ReadDir(s.fsys, name)
+	if err != nil {
+		s.notFound(ctx)
+		return
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("<!doctype html>\n<meta name=\"viewport\" content=\"width=device-width\">\n<pre>\n")
+	for _, entry := range entries {
+		entryName := entry.Name()
+		if entry.IsDir() {
+			entryName += "/"
+		}
+		link := url.URL{Path: entryName}
+		fmt.Fprintf(&buf, "<a href=\"%s\">%s</a>\n", link.String(), html.EscapeString(entryName))
+	}
+	buf.WriteString("</pre>\n")
+
+	ctx.SetStatusCode(http.StatusOK)
+	ctx.Response.Header("Content-Type", "text/html; charset=utf-8")
+	ctx.DisableInternalResponse()
+	ctx.writeHeaders()
+	w := *ctx.OriginalWriter
+	w.WriteHeader(http.StatusOK)
+	if ctx.Method != http.MethodHead {
+		w.Write(buf.Bytes())
+	}
+}
+
+// acceptsFallback 判断请求是否应回退到 SPA 索引页
+func (s *staticServer) acceptsFallback(ctx *Context, name string) bool {
+	if path.Ext(name) != "" {
+		return false
+	}
+	return strings.Contains(ctx.Request.Header.Get("Accept"), "text/html")
+}
+
+// notFound 文件不存在时交给服务器的 404 处理器，保证与 SetNotFound 行为一致
+func (s *staticServer) notFound(ctx *Context) {
+	ctx.SetStatusCode(http.StatusNotFound)
+	if ctx.Server != nil && len(ctx.Server.notFoundHandlers) > 0 {
+		ctx.insertRunners(ctx.Server.notFoundHandlers...)
+		return
+	}
+	ctx.SetResult("404 page not found")
+}
+
+// serveFile 写出文件内容，Range 和条件请求由 http.ServeContent 处理
+func (s *staticServer) serveFile(ctx *Context, name string, file fs.File, info fs.FileInfo) {
+	content, modTime, size := file, info.ModTime(), info.Size()
+	etagSuffix := ""
+
+	// 预压缩文件
+	if s.options.Precompressed {
+		ctx.Response.Header("Vary", "Accept-Encoding")
+		acceptEncoding := ctx.Request.Header.Get("Accept-Encoding")
+		for _, candidate := range precompressedEncodings {
+			if !acceptsEncoding(acceptEncoding, candidate.encoding) {
+				continue
+			}
+			compressed, compressedInfo, err := s.open(name + candidate.extension)
+			if err != nil || compressedInfo.IsDir() {
+				continue
+			}
+			defer compressed.Close()
+			content, modTime, size = compressed, compressedInfo.ModTime(), compressedInfo.Size()
+			etagSuffix = "-" + candidate.encoding
+			ctx.Response.Header("Content-Encoding", candidate.encoding)
+			break
+		}
+	}
+
+	readSeeker, err := toReadSeeker(content)
+	if err != nil {
+		ctx.SetStatusCode(http.StatusInternalServerError)
+		ctx.SetResult(err.Error())
+		return
+	}
+
+	etag, err := s.etag(name+etagSuffix, readSeeker, modTime, size)
+	etag = "\"" + etag + etagSuffix + "\""
+	if err != nil {
+		ctx.SetStatusCode(http.StatusInternalServerError)
+		ctx.SetResult(err.Error())
+		return
+	}
+	ctx.Response.Header("ETag", etag)
+	if s.options.MaxAge > 0 {
+		ctx.Response.Header("Cache-Control", "public, max-age="+strconv.Itoa(int(s.options.MaxAge.Seconds())))
+	}
+
+	ctx.DisableInternalResponse()
+	ctx.writeHeaders()
+	http.ServeContent(*ctx.OriginalWriter, ctx.OriginalRequest, path.Base(name), modTime, readSeeker)
+}
+
+// etag 计算不带引号的实体标签，有修改时间时使用修改时间和大小，否则使用内容摘要
+func (s *staticServer) etag(key string, content io.ReadSeeker, modTime time.Time, size int64) (string, error) {
+	if !modTime.IsZero() {
+		return fmt.Sprintf("%x-%x", modTime.UnixNano(), size), nil
+	}
+	if etag, ok := s.etags.Get(key); ok {
+		return etag, nil
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	etag := hex.EncodeToString(hash.Sum(nil)[:16])
+	s.etags.Set(key, etag)
+	return etag, nil
+}
+
+// toReadSeeker 将 fs.File 转换为 io.ReadSeeker，不支持 Seek 的文件会被读入内存
+func toReadSeeker(file fs.File) (io.ReadSeeker, error) {
+	if readSeeker, ok := file.(io.ReadSeeker); ok {
+		return readSeeker, nil
+	}
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(content), nil
+}
+
+// acceptsEncoding 判断 Accept-Encoding 是否接受指定编码（q=0 视为不接受）
+func acceptsEncoding(acceptEncoding, encoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), encoding) && strings.TrimSpace(name) != "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if value, err := strconv.ParseFloat(q, 64); err == nil && value == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package rest
+
+import (
+	"net/http"
+)
+
+// RouteInfo 已注册的路由信息
+type RouteInfo struct {
+	Method   string
+	Path     string
+	Handler  string // 最后一个处理器的名称
+	Disabled bool
+}
+
+// ServeHTTP 实现 http.Handler 接口，使用当前生效的路由表处理请求
+// 尚未构建路由表时会先调用 Reload
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	mux := s.mux.Load()
+	if mux == nil {
+		s.Reload()
+		mux = s.mux.Load()
+	}
+	mux.ServeHTTP(w, r)
+}
+
+// Reload 根据当前的路由树重新构建路由表并原子地替换
+// 正在处理的请求继续使用旧的路由表，不会中断连接
+//
+// 在运行期间修改路由树（如 Handle、Group、Use）后调用 Reload 使修改生效，
+// 修改路由树与 Reload 互斥，可以在任意 goroutine 中进行，修改在下一次 Reload 之前不会生效
+func (s *Server) Reload() {
+	s.reloadLock.Lock()
+	defer s.reloadLock.Unlock()
+
+	mux := http.NewServeMux()
+	registerRouteGroup(mux, &s.RouteGroup, s)
+	s.mux.Store(mux)
+}
+
+// Routes 返回当前路由表中的所有路由，尚未调用 Reload 或 Run 时为空
+func (s *Server) Routes() []RouteInfo {
+	s.reloadLock.Lock()
+	factories := s.flattenFactories
+	s.reloadLock.Unlock()
+
+	routes := make([]RouteInfo, 0, len(factories))
+	for _, factory := range factories {
+		handler := ""
+		if len(factory.HandlerNames) > 0 {
+			handler = factory.HandlerNames[len(factory.HandlerNames)-1]
+		}
+		routes = append(routes, RouteInfo{
+			Method:   factory.Method,
+			Path:     factory.Path,
+			Handler:  handler,
+			Disabled: s.isRouteDisabled(&factory),
+		})
+	}
+	return routes
+}
+
+// Disable 禁用当前组（包括子组）的所有路由，立即生效，不需要 Reload
+// 被禁用的路由交给 SetDisabledHandler 设置的处理器，可用于维护模式
+func (g *RouteGroup) Disable() {
+	g.disabled.Store(true)
+}
+
+// Enable 重新启用当前组
+func (g *RouteGroup) Enable() {
+	g.disabled.Store(false)
+}
+
+// IsDisabled 检查当前组是否被禁用，不考虑上级组
+func (g *RouteGroup) IsDisabled() bool {
+	return g.disabled.Load()
+}
+
+// DisableRoute 禁用单个路由，立即生效，path 为包含所有路由组前缀的完整路径
+//
+// 使用示例:
+//
+//	server.DisableRoute(http.MethodPost, "/v1/audio/origin")
+func (s *Server) DisableRoute(method, path string) {
+	s.disabledRoutes.Set(routeKey(method, path), true)
+}
+
+// EnableRoute 重新启用单个路由
+func (s *Server) EnableRoute(method, path string) {
+	s.disabledRoutes.Delete(routeKey(method, path))
+}
+
+// SetDisabledHandler 设置被禁用路由的处理器，执行前会先经过服务器级别的中间件
+// 如果未设置，将返回 503 状态码和错误信息
+func (s *Server) SetDisabledHandler(handlers ...HandlerFunc) {
+	s.disabledHandlers = handlers
+	s.disabledNames = make([]string, len(handlers))
+	for i, f := range handlers {
+		s.disabledNames[i] = funcName(f)
+	}
+}
+
+// routeKey 路由在禁用列表中的键
+func routeKey(method, path string) string {
+	return method + " " + path
+}
+
+// isRouteDisabled 检查路由本身或其所属的路由组是否被禁用
+func (s *Server) isRouteDisabled(factory *HandlerFactory) bool {
+	if factory.isGroupDisabled() {
+		return true
+	}
+	disabled, _ := s.disabledRoutes.Get(routeKey(factory.Method, factory.Path))
+	return disabled
+}
+
+// isGroupDisabled 检查路由所属的路由组是否被禁用
+func (f *HandlerFactory) isGroupDisabled() bool {
+	for _, group := range f.groups {
+		if group.disabled.Load() {
+			return true
+		}
+	}
+	return false
+}
+
+// disabledChain 返回被禁用路由的执行链，withPreRunners 为 true 时包含服务器级别的中间件
+func (s *Server) disabledChain(withPreRunners bool) ([]HandlerFunc, []string) {
+	handlers, names := s.disabledHandlers, s.disabledNames
+	if len(handlers) == 0 {
+		handlers, names = []HandlerFunc{defaultDisabledHandler}, []string{"disabled"}
+	}
+	if !withPreRunners {
+		return handlers, names
+	}
+
+	chain := make([]HandlerFunc, 0, len(s.PreRunnerChain)+len(handlers))
+	chain = append(chain, s.PreRunnerChain...)
+	chain = append(chain, handlers...)
+	chainNames := make([]string, 0, len(s.PreRunnerNames)+len(names))
+	chainNames = append(chainNames, s.PreRunnerNames...)
+	chainNames = append(chainNames, names...)
+	return chain, chainNames
+}
+
+// defaultDisabledHandler 默认的被禁用路由处理器
+func defaultDisabledHandler(ctx *Context) {
+	ctx.SetStatusCode(http.StatusServiceUnavailable)
+	ctx.SetResult("Service Unavailable: route is disabled")
+}
@@ -0,0 +1,302 @@
+package rest
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// VersionStrategy API 版本的识别方式
+type VersionStrategy int
+
+const (
+	// VersionByPath 通过路径前缀识别版本，如 /v1/users
+	VersionByPath VersionStrategy = iota
+	// VersionByAccept 通过 Accept 请求头中的媒体类型识别版本，
+	// 如 application/vnd.arima.v2+json 或 application/json; version=v2
+	VersionByAccept
+	// VersionByHeader 通过自定义请求头识别版本，如 X-API-Version: v2
+	VersionByHeader
+)
+
+// DefaultVersionHeader VersionByHeader 默认使用的请求头
+const DefaultVersionHeader = "X-API-Version"
+
+// apiVersionKey 在 Context.Memory 中存储当前请求 API 版本的键
+type apiVersionKey struct{}
+
+// VersioningOptions API 版本配置
+type VersioningOptions struct {
+	Strategy VersionStrategy
+	// Header VersionByHeader 使用的请求头，为空时使用 DefaultVersionHeader
+	Header string
+	// Vendor VersionByAccept 使用的厂商名，匹配 application/vnd.<Vendor>.<version>+json
+	// 为空时只识别媒体类型的 version 参数
+	Vendor string
+	// DefaultVersion 请求未指定版本时使用的版本，为空时使用最后声明的版本
+	// 仅对 VersionByAccept 和 VersionByHeader 生效
+	DefaultVersion string
+}
+
+// Deprecation API 版本弃用信息
+type Deprecation struct {
+	At     time.Time // 弃用时间，为零值时 Deprecation 头为 true
+	Sunset time.Time // 下线时间，为零值时不设置 Sunset 头
+	Link   string    // 弃用说明文档地址，为空时不设置 Link 头
+}
+
+// Versioning 一组按版本组织的路由
+type Versioning struct {
+	options  VersioningOptions
+	versions []*APIVersion
+	server   *Server
+}
+
+// APIVersion 单个 API 版本，未覆盖的路由会回退到上一个版本的处理器
+type APIVersion struct {
+	*RouteGroup
+
+	Name        string
+	deprecation atomic.Pointer[Deprecation] // 请求时读取，运行期间也可以修改
+}
+
+// Versioning 在当前组下创建版本化路由
+//
+// 使用示例:
+//
+//	api := server.Versioning(rest.VersioningOptions{Strategy: rest.VersionByHeader})
+//	v1 := api.Version("v1")
+//	v1.Get("/users", rest.Service[ListUsersV1]())
+//	v2 := api.Version("v2")
+//	v2.Get("/users", rest.Service[ListUsersV2]()) // 其余路由回退到 v1
+func (g *RouteGroup) Versioning(options VersioningOptions) *Versioning {
+	if options.Header == "" {
+		options.Header = DefaultVersionHeader
+	}
+	versioning := &Versioning{
+		options:  options,
+		versions: make([]*APIVersion, 0),
+		server:   g.server,
+	}
+	defer g.lockTree()()
+	g.versionings = append(g.versionings, versioning)
+	return versioning
+}
+
+// Version 声明一个新版本，声明顺序即版本先后顺序
+func (v *Versioning) Version(name string, preRunnerChain ...HandlerFunc) *APIVersion {
+	if v.server != nil {
+		v.server.reloadLock.Lock()
+		defer v.server.reloadLock.Unlock()
+	}
+	for _, version := range v.versions {
+		if version.Name == name {
+			panic(fmt.Sprintf("rest: duplicate API version %q", name))
+		}
+	}
+	group := NewRouteGroup(v.server, "", preRunnerChain...)
+	version := &APIVersion{
+		RouteGroup: &group,
+		Name:       name,
+	}
+	v.versions = append(v.versions, version)
+	return version
+}
+
+// Deprecate 将版本标记为已弃用，该版本的响应会携带 Deprecation、Sunset 和 Link 头
+func (a *APIVersion) Deprecate(deprecation Deprecation) *APIVersion {
+	a.deprecation.Store(&deprecation)
+	return a
+}
+
+// writeDeprecationHeaders 为已弃用版本设置响应头
+func (a *APIVersion) writeDeprecationHeaders(ctx *Context) {
+	deprecation := a.deprecation.Load()
+	if deprecation == nil {
+		return
+	}
+	if deprecation.At.IsZero() {
+		ctx.Response.Header("Deprecation", "true")
+	} else {
+		ctx.Response.Header("Deprecation", "@"+strconv.FormatInt(deprecation.At.Unix(), 10))
+	}
+	if !deprecation.Sunset.IsZero() {
+		ctx.Response.Header("Sunset", deprecation.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if deprecation.Link != "" {
+		ctx.Response.Header("Link", fmt.Sprintf("<%s>; rel=\"deprecation\"", deprecation.Link))
+	}
+}
+
+// APIVersion 获取当前请求命中的 API 版本名称，未使用版本化路由时返回空字符串
+func (c *Context) APIVersion() string {
+	if version, ok := c.Get(apiVersionKey{}); ok {
+		return version.(string)
+	}
+	return ""
+}
+
+// effectiveRoutes 计算每个版本实际生效的路由，未覆盖的路由继承上一个版本
+// 返回的 keys 为所有版本路由的并集，按首次声明的顺序排列
+func (v *Versioning) effectiveRoutes() (keys []string, routes []map[string]HandlerFactory) {
+	keys = make([]string, 0)
+	routes = make([]map[string]HandlerFactory, len(v.versions))
+	for i, version := range v.versions {
+		current := make(map[string]HandlerFactory)
+		if i > 0 {
+			for key, factory := range routes[i-1] {
+				current[key] = factory
+			}
+		}
+		for _, factory := range flattenFactories(version.RouteGroup, "", make([]HandlerFunc, 0), make([]string, 0)) {
+			key := factory.Method + " " + factory.Path
+			if !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+			current[key] = factory
+		}
+		routes[i] = current
+	}
+	return
+}
+
+// compile 将版本化路由展开为普通路由组，在 flattenFactories 时调用
+func (v *Versioning) compile() *RouteGroup {
+	group := NewRouteGroup(v.server, "")
+	if len(v.versions) == 0 {
+		return &group
+	}
+	keys, routes := v.effectiveRoutes()
+
+	// 路径前缀：每个版本独立注册一份完整的路由
+	if v.options.Strategy == VersionByPath {
+		for i, version := range v.versions {
+			for _, key := range keys {
+				factory, ok := routes[i][key]
+				if !ok {
+					continue
+				}
+				marker := func(ctx *Context) {
+					ctx.Set(apiVersionKey{}, version.Name)
+					version.writeDeprecationHeaders(ctx)
+				}
+				factory.Path = "/" + version.Name + factory.Path
+				factory.RunnerChain = append([]HandlerFunc{marker}, factory.RunnerChain...)
+				factory.HandlerNames = append([]string{"versionMarker"}, factory.HandlerNames...)
+				group.Factories = append(group.Factories, factory)
+			}
+		}
+		return &group
+	}
+
+	// 请求头：每个路由只注册一次，请求时根据版本选择处理器链
+	defaultIndex := len(v.versions) - 1
+	if v.options.DefaultVersion != "" {
+		defaultIndex = v.indexOf(v.options.DefaultVersion)
+		if defaultIndex < 0 {
+			panic(fmt.Sprintf("rest: default API version %q is not declared", v.options.DefaultVersion))
+		}
+	}
+	varyHeader := v.options.Header
+	if v.options.Strategy == VersionByAccept {
+		varyHeader = "Accept"
+	}
+	for _, key := range keys {
+		var sample HandlerFactory
+		candidates := make([]*HandlerFactory, len(v.versions))
+		for i := range v.versions {
+			if factory, ok := routes[i][key]; ok {
+				candidates[i] = &factory
+				sample = factory
+			}
+		}
+
+		dispatcher := func(ctx *Context) {
+			ctx.Response.Header("Vary", varyHeader)
+			index := defaultIndex
+			if name := v.requestedVersion(ctx); name != "" {
+				if index = v.indexOf(name); index < 0 {
+					ctx.SetStatusCode(http.StatusBadRequest)
+					ctx.SetResult("Unsupported API version: " + name)
+					ctx.Abort()
+					return
+				}
+			}
+			version := v.versions[index]
+			ctx.Set(apiVersionKey{}, version.Name)
+			version.writeDeprecationHeaders(ctx)
+
+			// 该路由在请求的版本中尚不存在
+			if candidates[index] == nil {
+				ctx.SetStatusCode(http.StatusNotFound)
+				ctx.Abort()
+				return
+			}
+			// 版本内的路由组被禁用
+			if candidates[index].isGroupDisabled() {
+				handlers, _ := v.server.disabledChain(false)
+				ctx.insertRunners(handlers...)
+				return
+			}
+			ctx.insertRunners(candidates[index].RunnerChain...)
+		}
+
+		lastName := ""
+		if len(sample.HandlerNames) > 0 {
+			lastName = sample.HandlerNames[len(sample.HandlerNames)-1]
+		}
+		group.Factories = append(group.Factories, HandlerFactory{
+			Path:         sample.Path,
+			Method:       sample.Method,
+			RunnerChain:  []HandlerFunc{dispatcher},
+			HandlerNames: []string{lastName},
+		})
+	}
+	return &group
+}
+
+// indexOf 查找版本下标，同时兼容带或不带 v 前缀的写法
+func (v *Versioning) indexOf(name string) int {
+	for i, version := range v.versions {
+		if version.Name == name || strings.TrimPrefix(version.Name, "v") == strings.TrimPrefix(name, "v") {
+			return i
+		}
+	}
+	return -1
+}
+
+// requestedVersion 从请求中解析客户端要求的版本，未指定时返回空字符串
+func (v *Versioning) requestedVersion(ctx *Context) string {
+	switch v.options.Strategy {
+	case VersionByHeader:
+		return strings.TrimSpace(ctx.Request.Header.Get(v.options.Header))
+	case VersionByAccept:
+		for _, accept := range strings.Split(ctx.Request.Header.Get("Accept"), ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accept))
+			if err != nil {
+				continue
+			}
+			if version, ok := params["version"]; ok && version != "" {
+				return version
+			}
+			// application/vnd.<vendor>.<version>+json
+			if v.options.Vendor != "" {
+				prefix := "application/vnd." + strings.ToLower(v.options.Vendor) + "."
+				if rest, ok := strings.CutPrefix(mediaType, prefix); ok {
+					if pos := strings.Index(rest, "+"); pos != -1 {
+						rest = rest[:pos]
+					}
+					if rest != "" {
+						return rest
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
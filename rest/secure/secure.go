@@ -0,0 +1,114 @@
+// Package secure 提供设置安全相关响应头的中间件
+//
+// 默认值针对 JSON API：禁止加载任何资源、禁止被嵌入页面、不发送 Referer。
+// 返回 HTML 页面的路由组可以再次使用中间件覆盖，后设置的值会替换先设置的值。
+package secure
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+
+	"github.com/akagiyui/go-together/rest"
+)
+
+// Disabled 将选项设置为 Disabled 时不发送对应的响应头，并移除外层中间件已设置的值
+const Disabled = "-"
+
+// NoncePlaceholder ContentSecurityPolicy 中的占位符，每个请求会替换为新生成的 nonce
+const NoncePlaceholder = "{nonce}"
+
+// 默认值，适用于只返回 JSON 的接口
+const (
+	DefaultStrictTransportSecurity = "max-age=31536000; includeSubDomains"
+	DefaultContentSecurityPolicy   = "default-src 'none'; frame-ancestors 'none'"
+	DefaultContentTypeOptions      = "nosniff"
+	DefaultReferrerPolicy          = "no-referrer"
+	DefaultPermissionsPolicy       = "camera=(), microphone=(), geolocation=(), payment=(), usb=()"
+	DefaultFrameOptions            = "DENY"
+)
+
+// Options 安全响应头配置，为空的字段使用对应的默认值，设置为 Disabled 时不发送
+type Options struct {
+	// StrictTransportSecurity 默认为 DefaultStrictTransportSecurity，浏览器会忽略通过 HTTP 明文收到的该响应头
+	StrictTransportSecurity string
+	// ContentSecurityPolicy 默认为 DefaultContentSecurityPolicy，可以包含 NoncePlaceholder
+	ContentSecurityPolicy string
+	// ContentTypeOptions 对应 X-Content-Type-Options，默认为 DefaultContentTypeOptions
+	ContentTypeOptions string
+	// ReferrerPolicy 默认为 DefaultReferrerPolicy
+	ReferrerPolicy string
+	// PermissionsPolicy 默认为 DefaultPermissionsPolicy
+	PermissionsPolicy string
+	// FrameOptions 对应 X-Frame-Options，默认为 DefaultFrameOptions
+	FrameOptions string
+}
+
+// nonceKey 当前请求的 nonce 在 ctx.Memory 中的键
+type nonceKey struct{}
+
+// header 响应头名称和值
+type header struct {
+	name  string
+	value string
+}
+
+// Middleware 创建安全响应头中间件
+//
+// 使用示例:
+//
+//	server.Use(secure.Middleware(secure.Options{}))
+//
+//	// 返回页面的路由组放宽 CSP，脚本通过 nonce 加载
+//	pages := server.Group("/pages")
+//	pages.Use(secure.Middleware(secure.Options{
+//	    ContentSecurityPolicy: "default-src 'self'; script-src 'nonce-{nonce}'",
+//	    FrameOptions:          "SAMEORIGIN",
+//	}))
+func Middleware(options Options) rest.HandlerFunc {
+	headers := []header{
+		{"Strict-Transport-Security", withDefault(options.StrictTransportSecurity, DefaultStrictTransportSecurity)},
+		{"Content-Security-Policy", withDefault(options.ContentSecurityPolicy, DefaultContentSecurityPolicy)},
+		{"X-Content-Type-Options", withDefault(options.ContentTypeOptions, DefaultContentTypeOptions)},
+		{"Referrer-Policy", withDefault(options.ReferrerPolicy, DefaultReferrerPolicy)},
+		{"Permissions-Policy", withDefault(options.PermissionsPolicy, DefaultPermissionsPolicy)},
+		{"X-Frame-Options", withDefault(options.FrameOptions, DefaultFrameOptions)},
+	}
+
+	return func(ctx *rest.Context) {
+		for _, h := range headers {
+			value := h.value
+			if value == Disabled {
+				ctx.Headers.Del(h.name)
+				continue
+			}
+			if strings.Contains(value, NoncePlaceholder) {
+				value = strings.ReplaceAll(value, NoncePlaceholder, Nonce(ctx))
+			}
+			ctx.Headers.Set(h.name, value)
+		}
+	}
+}
+
+// Nonce 返回当前请求的 CSP nonce，首次调用时生成，同一请求内多次调用返回相同的值
+// 用于在页面模板中为 <script nonce="..."> 设置与 Content-Security-Policy 一致的值
+func Nonce(ctx *rest.Context) string {
+	if nonce, ok := ctx.Get(nonceKey{}); ok {
+		return nonce.(string)
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	nonce := base64.StdEncoding.EncodeToString(b)
+	ctx.Set(nonceKey{}, nonce)
+	return nonce
+}
+
+// withDefault 为空时返回默认值
+func withDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
@@ -3,13 +3,16 @@ package rest
 import (
 	"encoding/json"
 	"fmt"
-	"net"
 	"net/http"
 	"reflect"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/akagiyui/go-together/common/cache"
 )
 
 // Server HTTP 服务器
@@ -24,6 +27,31 @@ type Server struct {
 
 	// 校验错误处理器
 	validationErrorHandler func(*Context, error)
+
+	// 依赖提供者，用于填充带有 inject 标签的字段
+	providers *cache.Map[reflect.Type, provider]
+
+	// 请求追踪器
+	tracer Tracer
+
+	// 超时处理器
+	timeoutHandler func(*Context)
+
+	// 路由表
+	mux              atomic.Pointer[http.ServeMux] // 当前生效的路由表，Reload 时整体替换
+	reloadLock       sync.Mutex                    // 保护路由树，修改路由树和 Reload 构建路由表时持有
+	disabledRoutes   *cache.Map[string, bool]      // 被禁用的路由，键为 "METHOD /path"
+	disabledHandlers []HandlerFunc
+	disabledNames    []string
+
+	// mock 模式配置，为 nil 时不启用
+	mock *MockOptions
+
+	// 生命周期钩子
+	onRequest  []HandlerFunc
+	onBind     []func(*Context, any)
+	onResponse []HandlerFunc
+	onError    []func(*Context, error)
 }
 
 // NewServer 创建一个新的服务器实例
@@ -37,6 +65,23 @@ func NewServer() *Server {
 		notFoundNames:    nil,
 
 		validationErrorHandler: nil,
+
+		providers: cache.NewMap[reflect.Type, provider](),
+
+		tracer: nil,
+
+		timeoutHandler: nil,
+
+		disabledRoutes:   cache.NewMap[string, bool](),
+		disabledHandlers: nil,
+		disabledNames:    nil,
+
+		mock: nil,
+
+		onRequest:  nil,
+		onBind:     nil,
+		onResponse: nil,
+		onError:    nil,
 	}
 	server.RouteGroup.server = server
 
@@ -56,8 +101,14 @@ func (s *Server) SetValidationErrorHandler(handler func(*Context, error)) {
 // prePreRunnerChain 上一级路由组的前置 handler 链
 // prePreRunnerNames 上一级路由组的前置 handler 名称链
 func flattenFactories(group *RouteGroup, preBasePath string, prePreRunnerChain []HandlerFunc, prePreRunnerNames []string) []HandlerFactory {
-	factories := make([]HandlerFactory, 0)                                   // 这一级路由组的所有路由
-	thisBasePath := preBasePath + group.BasePath                             // 当前路由组的路径
+	factories := make([]HandlerFactory, 0)       // 这一级路由组的所有路由
+	thisBasePath := preBasePath + group.BasePath // 当前路由组的路径
+	// 设置了超时的路由组，在组内的前置 handler 之前插入超时中间件
+	if group.timeout > 0 {
+		timeout := Timeout(group.timeout)
+		prePreRunnerChain = append(prePreRunnerChain[:len(prePreRunnerChain):len(prePreRunnerChain)], timeout)
+		prePreRunnerNames = append(prePreRunnerNames[:len(prePreRunnerNames):len(prePreRunnerNames)], funcName(timeout))
+	}
 	thisPreRunnerChain := append(prePreRunnerChain, group.PreRunnerChain...) // 当前路由组的前置 handler 链
 	thisPreRunnerNames := append(prePreRunnerNames, group.PreRunnerNames...) // 当前路由组的前置 handler 名称链
 	// 处理当前路由组的路由
@@ -82,50 +133,47 @@ func flattenFactories(group *RouteGroup, preBasePath string, prePreRunnerChain [
 	for _, childGroup := range group.ChildGroups {
 		factories = append(factories, flattenFactories(childGroup, thisBasePath, thisPreRunnerChain, thisPreRunnerNames)...)
 	}
+	// 展开版本化路由
+	for _, versioning := range group.versionings {
+		factories = append(factories, flattenFactories(versioning.compile(), thisBasePath, thisPreRunnerChain, thisPreRunnerNames)...)
+	}
+	// 记录路由所属的路由组
+	for i := range factories {
+		factories[i].groups = append([]*RouteGroup{group}, factories[i].groups...)
+	}
 	return factories
 }
 
 // Run 启动 HTTP 服务器
 func (s *Server) Run(addr string) error {
-	mux := http.NewServeMux()
-	registerRouteGroup(mux, &s.RouteGroup, s) // 处理所有注册的 handler
-
-	// 输出所有注册的路由
-	if s.Debug {
-		// 计算最长路径长度，用于对齐
-		endpointMaxLen := 0
-		for _, factory := range s.flattenFactories {
-			if len(factory.Path) > endpointMaxLen {
-				endpointMaxLen = len(factory.Path)
-			}
-		}
+	return s.RunWithOptions(RunOptions{Addr: addr})
+}
 
-		// 输出所有注册的路由
-		for _, factory := range s.flattenFactories {
-			handlerCount := len(factory.RunnerChain)
-			// 优先使用 HandlerNames 中的最后一个名称，如果没有则使用反射获取
-			var lastHandlerName string
-			if len(factory.HandlerNames) > 0 && len(factory.HandlerNames) == handlerCount {
-				// HandlerNames 与 RunnerChain 长度一致时，使用存储的名称
-				lastHandlerName = factory.HandlerNames[handlerCount-1]
-			} else {
-				// 否则使用反射获取函数名称
-				lastHandlerName = runtime.FuncForPC(reflect.ValueOf(factory.RunnerChain[handlerCount-1]).Pointer()).Name()
-			}
-			// 使用格式化字符串实现左对齐
-			fmt.Printf("[%7s] %-*s --> %s (%d handlers)\n", factory.Method, endpointMaxLen, factory.Path, lastHandlerName, handlerCount)
+// printRoutes 输出所有注册的路由
+func (s *Server) printRoutes() {
+	// 计算最长路径长度，用于对齐
+	endpointMaxLen := 0
+	for _, factory := range s.flattenFactories {
+		if len(factory.Path) > endpointMaxLen {
+			endpointMaxLen = len(factory.Path)
 		}
 	}
 
-	if addr == "" {
-		addr = ":http"
-	}
-	ln, err := net.Listen("tcp", addr)
-	if err != nil {
-		return err
+	// 输出所有注册的路由
+	for _, factory := range s.flattenFactories {
+		handlerCount := len(factory.RunnerChain)
+		// 优先使用 HandlerNames 中的最后一个名称，如果没有则使用反射获取
+		var lastHandlerName string
+		if len(factory.HandlerNames) > 0 && len(factory.HandlerNames) == handlerCount {
+			// HandlerNames 与 RunnerChain 长度一致时，使用存储的名称
+			lastHandlerName = factory.HandlerNames[handlerCount-1]
+		} else {
+			// 否则使用反射获取函数名称
+			lastHandlerName = runtime.FuncForPC(reflect.ValueOf(factory.RunnerChain[handlerCount-1]).Pointer()).Name()
+		}
+		// 使用格式化字符串实现左对齐
+		fmt.Printf("[%7s] %-*s --> %s (%d handlers)\n", factory.Method, endpointMaxLen, factory.Path, lastHandlerName, handlerCount)
 	}
-	defer ln.Close()
-	return http.Serve(ln, mux)
 }
 
 func registerRouteGroup(mux *http.ServeMux, group *RouteGroup, server *Server) {
@@ -140,68 +188,124 @@ func registerRouteGroup(mux *http.ServeMux, group *RouteGroup, server *Server) {
 			pattern = fmt.Sprintf("%s %s", factory.Method, factory.Path)
 		}
 
-		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
-			ctx := NewContext(r, &w, server, factory.RunnerChain) // 创建上下文
-
-			defer func() {
-				if err := recover(); err != nil {
-					fmt.Println(err)
-					w.WriteHeader(http.StatusInternalServerError)
-					w.Write([]byte("Internal Server Error"))
-				}
-			}()
-
-			// 优先使用 HandlerNames 中的最后一个名称，如果没有则使用反射获取
-			var lastHandlerName string
-			if len(factory.HandlerNames) > 0 && len(factory.HandlerNames) == len(factory.RunnerChain) {
-				lastHandlerName = factory.HandlerNames[len(factory.HandlerNames)-1]
-			} else {
-				lastHandlerName = runtime.FuncForPC(reflect.ValueOf(factory.RunnerChain[len(factory.RunnerChain)-1]).Pointer()).Name()
-			}
-			// 倒查字符串，如果先找到.，就取出.后面的字符串，如果先找到/就取出/后面的字符串
-			if pos := strings.LastIndex(lastHandlerName, "."); pos != -1 {
-				lastHandlerName = lastHandlerName[pos+1:]
-			} else if pos := strings.LastIndex(lastHandlerName, "/"); pos != -1 {
-				lastHandlerName = lastHandlerName[pos+1:]
-			}
+		server.checkDependencies(factory.RunnerChain, pattern)
 
-			// dispatch request
-			contextGoWithLog(ctx, server, lastHandlerName)
+		// 处理器名称和路径参数名在注册时解析，避免每个请求重复计算
+		lastHandlerName := shortHandlerName(factory)
+		pathKeys := patternKeys(factory.Path)
 
-			// response
-			if !ctx.disableInternalResponse {
-				ctx.writeHeaders()
-				server.writeResponse(w, ctx.Result, ctx)
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			runnerChain, runnerNames := factory.RunnerChain, factory.HandlerNames
+			if server.isRouteDisabled(&factory) {
+				runnerChain, runnerNames = server.disabledChain(true)
 			}
+			ctx := acquireContext(w, r, server, runnerChain, pathKeys) // 创建上下文
+			defer releaseContext(ctx)
+			ctx.Pattern = factory.Path
+			ctx.runnerNames = runnerNames
+
+			serveContext(ctx, server, lastHandlerName)
 		})
 	}
 
 	// 注册 404 处理器（捕获所有未匹配的请求）
 	if len(server.notFoundHandlers) > 0 {
-		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-			handlers := make([]HandlerFunc, 0, len(server.PreRunnerChain)+len(server.notFoundHandlers))
-			handlers = append(handlers, server.PreRunnerChain...)
-			handlers = append(handlers, server.notFoundHandlers...)
+		handlers := make([]HandlerFunc, 0, len(server.PreRunnerChain)+len(server.notFoundHandlers))
+		handlers = append(handlers, server.PreRunnerChain...)
+		handlers = append(handlers, server.notFoundHandlers...)
 
-			ctx := NewContext(r, &w, server, handlers)
+		names := make([]string, 0, len(server.PreRunnerNames)+len(server.notFoundNames))
+		names = append(names, server.PreRunnerNames...)
+		names = append(names, server.notFoundNames...)
+		server.checkDependencies(handlers, "404 handler")
+
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			ctx := acquireContext(w, r, server, handlers, nil)
+			defer releaseContext(ctx)
+			ctx.runnerNames = names
 			ctx.SetStatusCode(http.StatusNotFound) // 默认设置 404 状态码
-			defer func() {
-				if err := recover(); err != nil {
-					fmt.Println(err)
-					w.WriteHeader(http.StatusInternalServerError)
-					w.Write([]byte("Internal Server Error"))
-				}
-			}()
-
-			// dispatch request
-			contextGoWithLog(ctx, server, "404")
-
-			// response
+
+			serveContext(ctx, server, "404")
+		})
+	}
+}
+
+// serveContext 执行处理器链并写出响应，处理器 panic 时返回 500
+func serveContext(ctx *Context, server *Server, lastHandlerName string) {
+	if server.tracer != nil {
+		end := server.tracer.StartRequest(ctx, lastHandlerName)
+		defer end()
+	}
+	defer ctx.runAfterResponse()
+
+	defer func() {
+		if err := recover(); err != nil {
+			// 与 net/http 一致，http.ErrAbortHandler 用于中断已经开始写出的响应，交给 net/http 静默关闭连接
+			if err == http.ErrAbortHandler {
+				panic(err)
+			}
+			fmt.Println(err)
+			ctx.SetStatusCode(http.StatusInternalServerError)
+			closeReplacedFile(ctx.Result, nil)
+			ctx.SetResult("Internal Server Error")
+			server.runErrorHooks(ctx, panicError(err))
 			if !ctx.disableInternalResponse {
 				ctx.writeHeaders()
-				server.writeResponse(w, ctx.Result, ctx)
+				server.writeResponse(*ctx.OriginalWriter, ctx.Result, ctx)
 			}
-		})
+		}
+	}()
+
+	server.runRequestHooks(ctx)
+
+	// dispatch request
+	contextGoWithLog(ctx, server, lastHandlerName)
+
+	// response
+	if !ctx.disableInternalResponse {
+		result := ctx.Result
+		server.runResponseHooks(ctx)
+		closeReplacedFile(result, ctx.Result)
+		ctx.writeHeaders()
+		server.writeResponse(*ctx.OriginalWriter, ctx.Result, ctx)
+	}
+}
+
+// shortHandlerName 获取路由最后一个处理器的短名称，用于日志和追踪
+func shortHandlerName(factory HandlerFactory) string {
+	// 优先使用 HandlerNames 中的最后一个名称，如果没有则使用反射获取
+	var lastHandlerName string
+	if len(factory.HandlerNames) > 0 && len(factory.HandlerNames) == len(factory.RunnerChain) {
+		lastHandlerName = factory.HandlerNames[len(factory.HandlerNames)-1]
+	} else {
+		lastHandlerName = runtime.FuncForPC(reflect.ValueOf(factory.RunnerChain[len(factory.RunnerChain)-1]).Pointer()).Name()
+	}
+	// 倒查字符串，如果先找到.，就取出.后面的字符串，如果先找到/就取出/后面的字符串
+	if pos := strings.LastIndex(lastHandlerName, "."); pos != -1 {
+		lastHandlerName = lastHandlerName[pos+1:]
+	} else if pos := strings.LastIndex(lastHandlerName, "/"); pos != -1 {
+		lastHandlerName = lastHandlerName[pos+1:]
+	}
+	return lastHandlerName
+}
+
+// patternKeys 解析路由路径中的参数名，如 /users/{id}/{path...} 得到 [id path]
+func patternKeys(pattern string) []string {
+	var keys []string
+	for {
+		start := strings.IndexByte(pattern, '{')
+		if start < 0 {
+			return keys
+		}
+		end := strings.IndexByte(pattern[start:], '}')
+		if end < 0 {
+			return keys
+		}
+		name := strings.TrimSuffix(pattern[start+1:start+end], "...")
+		if name != "" && name != "$" {
+			keys = append(keys, name)
+		}
+		pattern = pattern[start+end+1:]
 	}
 }
 
@@ -215,16 +319,18 @@ func (s *Server) writeResponse(w http.ResponseWriter, result any, ctx *Context)
 	// 调用 w.Write 时，如果没有调用 WriteHeader，会自动调用 WriteHeader(200)
 	// 在 w.WriteHeader 后，就不能再修改 Header 了
 
-	// 判断类型
+	// 判断类型，处理器已设置 Content-Type 时不覆盖
 	switch result := result.(type) {
 	case string:
-		w.Header().Set("Content-Type", "text/plain")
+		setDefaultContentType(w, "text/plain")
 		w.WriteHeader(ctx.StatusCode)
 		w.Write([]byte(result))
 	case int:
-		w.Header().Set("Content-Type", "text/plain")
+		setDefaultContentType(w, "text/plain")
 		w.WriteHeader(ctx.StatusCode)
 		w.Write([]byte(strconv.Itoa(result)))
+	case *FileResult:
+		s.writeFile(w, result, ctx)
 	default:
 		b, err := json.Marshal(result)
 		if err != nil {
@@ -232,12 +338,19 @@ func (s *Server) writeResponse(w http.ResponseWriter, result any, ctx *Context)
 			w.Write([]byte(err.Error()))
 			return
 		}
-		w.Header().Set("Content-Type", "application/json")
+		setDefaultContentType(w, "application/json")
 		w.WriteHeader(ctx.StatusCode)
 		w.Write(b)
 	}
 }
 
+// setDefaultContentType 仅在响应未设置 Content-Type 时设置
+func setDefaultContentType(w http.ResponseWriter, contentType string) {
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+}
+
 // SetNotFound 设置 404 处理器
 func (s *Server) SetNotFound(handlers ...HandlerFunc) {
 	s.notFoundHandlers = handlers
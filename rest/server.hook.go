@@ -0,0 +1,78 @@
+package rest
+
+import (
+	"fmt"
+)
+
+// OnRequest 注册请求钩子，在执行处理器链（包括全局中间件）之前调用
+// 钩子中调用 ctx.Abort() 可以跳过处理器链，此时仍会调用 OnResponse 并写出响应
+func (s *Server) OnRequest(hooks ...HandlerFunc) {
+	s.onRequest = append(s.onRequest, hooks...)
+}
+
+// OnBind 注册参数绑定钩子，在 Service、Struct 完成参数解析之后、数据校验之前调用
+// request 为绑定后的处理器结构体指针，钩子中调用 ctx.Abort() 可以阻止处理器执行
+func (s *Server) OnBind(hooks ...func(ctx *Context, request any)) {
+	s.onBind = append(s.onBind, hooks...)
+}
+
+// OnResponse 注册响应钩子，在处理器链结束后、写出响应之前调用，可以修改 ctx.Result 和 ctx.StatusCode
+// 钩子总是在所有中间件之后执行，与中间件的注册顺序无关；处理器已自行写出响应或发生 panic 时不会调用
+func (s *Server) OnResponse(hooks ...HandlerFunc) {
+	s.onResponse = append(s.onResponse, hooks...)
+}
+
+// OnError 注册错误钩子，在处理器 panic 或 ServiceHandlerInterface 的 Do 方法返回错误时调用
+// panic 时 ctx 已被设置为 500 和 "Internal Server Error"，钩子可以替换为自定义的错误响应
+func (s *Server) OnError(hooks ...func(ctx *Context, err error)) {
+	s.onError = append(s.onError, hooks...)
+}
+
+// runRequestHooks 依次执行请求钩子，任意钩子中止后不再执行后续钩子
+func (s *Server) runRequestHooks(ctx *Context) {
+	for _, hook := range s.onRequest {
+		hook(ctx)
+		if ctx.IsAborted() {
+			return
+		}
+	}
+}
+
+// runBindHooks 依次执行参数绑定钩子，返回 false 表示钩子中止了请求
+func (s *Server) runBindHooks(ctx *Context, request any) bool {
+	if s == nil {
+		return true
+	}
+	for _, hook := range s.onBind {
+		hook(ctx, request)
+		if ctx.IsAborted() {
+			return false
+		}
+	}
+	return true
+}
+
+// runResponseHooks 依次执行响应钩子
+func (s *Server) runResponseHooks(ctx *Context) {
+	for _, hook := range s.onResponse {
+		hook(ctx)
+	}
+}
+
+// runErrorHooks 依次执行错误钩子
+func (s *Server) runErrorHooks(ctx *Context, err error) {
+	if s == nil {
+		return
+	}
+	for _, hook := range s.onError {
+		hook(ctx, err)
+	}
+}
+
+// panicError 将 recover 得到的值转换为 error
+func panicError(recovered any) error {
+	if err, ok := recovered.(error); ok {
+		return fmt.Errorf("panic: %w", err)
+	}
+	return fmt.Errorf("panic: %v", recovered)
+}
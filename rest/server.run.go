@@ -0,0 +1,117 @@
+package rest
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// RunOptions 服务器启动配置
+type RunOptions struct {
+	// Addr 监听地址，默认为 :http，启用 TLS 时默认为 :https
+	Addr string
+
+	// H2C 是否允许明文 HTTP/2（h2c prior knowledge），用于服务间内部通信
+	// 启用后同一端口仍然支持 HTTP/1.1
+	H2C bool
+
+	// CertFile 和 KeyFile 同时设置时启用 TLS（同时支持 HTTP/1.1 和 HTTP/2）
+	// 证书文件变化后会自动重新加载，无需重启服务器
+	CertFile string
+	KeyFile  string
+	// CertReloadInterval 检查证书文件变化的最短间隔，默认 10 秒
+	CertReloadInterval time.Duration
+	// TLSConfig 基础 TLS 配置，为 nil 时使用 TLS 1.2 及以上的默认配置
+	TLSConfig *tls.Config
+
+	// ConfigureServer 启动前对 http.Server 的额外配置，如超时时间和 ErrorLog
+	ConfigureServer func(*http.Server)
+}
+
+// RunWithOptions 按照配置启动服务器
+//
+// 使用示例:
+//
+//	server.RunWithOptions(rest.RunOptions{
+//		Addr:     ":8443",
+//		CertFile: "/etc/arima/tls.crt",
+//		KeyFile:  "/etc/arima/tls.key",
+//	})
+func (s *Server) RunWithOptions(options RunOptions) error {
+	s.Reload() // 处理所有注册的 handler
+	if s.Debug {
+		s.printRoutes()
+	}
+
+	httpServer, err := s.NewHTTPServer(options)
+	if err != nil {
+		return err
+	}
+
+	useTLS := httpServer.TLSConfig != nil
+	addr := httpServer.Addr
+	if addr == "" {
+		addr = ":http"
+		if useTLS {
+			addr = ":https"
+		}
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+
+	if useTLS {
+		err = httpServer.ServeTLS(ln, "", "")
+	} else {
+		err = httpServer.Serve(ln)
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// NewHTTPServer 按照配置创建使用当前服务器处理请求的 http.Server，便于自行管理监听和优雅关闭
+//
+// 返回的 http.Server 的 TLSConfig 可以直接用于 HTTP/3 等其他协议的服务器，
+// 与 TCP 上的服务共享同一份自动重新加载的证书。
+func (s *Server) NewHTTPServer(options RunOptions) (*http.Server, error) {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(options.H2C)
+
+	httpServer := &http.Server{
+		Addr:      options.Addr,
+		Handler:   s,
+		Protocols: protocols,
+	}
+
+	if options.CertFile != "" || options.KeyFile != "" {
+		if options.CertFile == "" || options.KeyFile == "" {
+			return nil, errors.New("rest: both CertFile and KeyFile must be set to enable TLS")
+		}
+		reloader, err := NewCertReloader(options.CertFile, options.KeyFile, options.CertReloadInterval)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig := options.TLSConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		} else {
+			tlsConfig = tlsConfig.Clone()
+		}
+		tlsConfig.GetCertificate = reloader.GetCertificate
+		httpServer.TLSConfig = tlsConfig
+	}
+
+	if options.ConfigureServer != nil {
+		options.ConfigureServer(httpServer)
+	}
+	return httpServer, nil
+}
@@ -0,0 +1,111 @@
+package rest
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// CertReloader 从磁盘加载 TLS 证书，文件变化后自动重新加载
+// 检查在 TLS 握手时进行，两次检查之间至少间隔 interval，不会启动额外的 goroutine
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu          sync.RWMutex
+	certificate *tls.Certificate
+	certModTime time.Time
+	keyModTime  time.Time
+	lastCheck   time.Time
+}
+
+// NewCertReloader 加载证书并创建 CertReloader，interval 小于等于 0 时默认为 10 秒
+//
+// 使用示例:
+//
+//	reloader, err := rest.NewCertReloader("tls.crt", "tls.key", 0)
+//	tlsConfig := &tls.Config{GetCertificate: reloader.GetCertificate}
+func NewCertReloader(certFile, keyFile string, interval time.Duration) (*CertReloader, error) {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	r := &CertReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload 立即重新加载证书，加载失败时保留原有证书
+func (r *CertReloader) Reload() error {
+	certModTime, keyModTime, err := r.modTimes()
+	if err != nil {
+		return err
+	}
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("rest: load certificate: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.certificate = &certificate
+	r.certModTime = certModTime
+	r.keyModTime = keyModTime
+	r.lastCheck = time.Now()
+	return nil
+}
+
+// GetCertificate 用于 tls.Config.GetCertificate
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.maybeReload()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.certificate, nil
+}
+
+// maybeReload 距离上次检查超过 interval 且文件修改时间变化时重新加载
+func (r *CertReloader) maybeReload() {
+	r.mu.Lock()
+	if time.Since(r.lastCheck) < r.interval {
+		r.mu.Unlock()
+		return
+	}
+	r.lastCheck = time.Now()
+	certModTime, keyModTime := r.certModTime, r.keyModTime
+	r.mu.Unlock()
+
+	newCertModTime, newKeyModTime, err := r.modTimes()
+	if err != nil {
+		fmt.Printf("rest: check certificate: %v\n", err)
+		return
+	}
+	if newCertModTime.Equal(certModTime) && newKeyModTime.Equal(keyModTime) {
+		return
+	}
+	// 证书和私钥可能不是同时写入的，加载失败时保留原有证书，下次检查时重试
+	if err := r.Reload(); err != nil {
+		fmt.Printf("rest: reload certificate: %v\n", err)
+	}
+}
+
+// modTimes 获取证书和私钥文件的修改时间
+func (r *CertReloader) modTimes() (time.Time, time.Time, error) {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return certInfo.ModTime(), keyInfo.ModTime(), nil
+}
@@ -0,0 +1,44 @@
+package rest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/akagiyui/go-together/rest"
+)
+
+// benchGetUser 绑定路径参数的处理器
+type benchGetUser struct {
+	ID int64 `path:"id"`
+}
+
+func (r benchGetUser) Do() (any, error) { return "ok", nil }
+
+// discardWriter 丢弃响应输出，避免 httptest.ResponseRecorder 的分配影响结果
+type discardWriter struct{ header http.Header }
+
+func (w *discardWriter) Header() http.Header         { return w.header }
+func (w *discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardWriter) WriteHeader(int)             {}
+
+// benchmarkServe 对带中间件的服务器重复调用 ServeHTTP，复用同一个请求
+func benchmarkServe(b *testing.B, path string) {
+	s := rest.NewServer()
+	s.Use(func(ctx *rest.Context) { ctx.Next() })
+	s.Get("/ping", func(ctx *rest.Context) { ctx.SetResult("pong") })
+	s.Get("/users/{id}", rest.Service[benchGetUser]())
+
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	w := &discardWriter{header: make(http.Header)}
+	s.ServeHTTP(w, r) // 构建路由表
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		clear(w.header)
+		s.ServeHTTP(w, r)
+	}
+}
+
+func BenchmarkStatic(b *testing.B) { benchmarkServe(b, "/ping") }
+func BenchmarkParam(b *testing.B)  { benchmarkServe(b, "/users/42") }
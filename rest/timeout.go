@@ -0,0 +1,86 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Timeout 超时中间件，为后续处理器设置截止时间，到期后取消 ctx.Context()
+//
+// 超时是协作式的：处理器需要通过 ctx.Context()（或 `inject:""` 注入的 context.Context）
+// 感知取消并尽快返回。处理器返回后如果已经超时，会交给 SetTimeoutHandler 设置的处理器，
+// 默认返回 503，响应仍然经过外层中间件和正常的响应写出流程。
+//
+// 嵌套使用时，内层的超时会替换外层的截止时间（可以更长也可以更短），内层返回后恢复外层的截止时间。
+//
+// 使用示例:
+//
+//	router.Get("/probe", rest.Timeout(30*time.Second), rest.Service[ProbeRequest]())
+func Timeout(d time.Duration) HandlerFunc {
+	handler := func(ctx *Context) {
+		if ctx.OriginalRequest == nil {
+			ctx.Next()
+			return
+		}
+		previous := ctx.Context()
+		timeoutCtx, cancel := context.WithTimeout(context.WithoutCancel(previous), d)
+		// 客户端断开时仍然需要取消
+		stop := context.AfterFunc(ctx.requestContext, cancel)
+		defer func() {
+			stop()
+			cancel()
+		}()
+
+		ctx.SetContext(timeoutCtx)
+		ctx.Next()
+
+		timedOut := errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) && ctx.requestContext.Err() == nil
+		if ctx.Context() == timeoutCtx {
+			ctx.SetContext(previous)
+		}
+		if timedOut && !ctx.timeoutDisabled && !ctx.disableInternalResponse {
+			ctx.Server.handleTimeout(ctx)
+		}
+	}
+	registerHandlerName(handler, "rest.Timeout")
+	return handler
+}
+
+// Timeout 为当前组（包括子组）的所有路由设置超时，子组可以再次设置以覆盖
+func (g *RouteGroup) Timeout(d time.Duration) {
+	defer g.lockTree()()
+	g.timeout = d
+}
+
+// SetTimeoutHandler 设置请求超时后的处理器
+// 如果未设置，将返回 503 状态码和错误信息
+func (s *Server) SetTimeoutHandler(handler func(*Context)) {
+	s.timeoutHandler = handler
+}
+
+// handleTimeout 处理超时的请求
+func (s *Server) handleTimeout(ctx *Context) {
+	if s != nil && s.timeoutHandler != nil {
+		s.timeoutHandler(ctx)
+		return
+	}
+	ctx.SetStatusCode(http.StatusServiceUnavailable)
+	ctx.SetResult("Service Unavailable: request timed out")
+}
+
+// DisableTimeout 移除当前请求的截止时间，用于流式响应等长时间运行的处理器
+// ctx.Context() 仍会在客户端断开时取消
+func (c *Context) DisableTimeout() {
+	if c.timeoutDisabled || c.OriginalRequest == nil {
+		return
+	}
+	c.timeoutDisabled = true
+	if _, hasDeadline := c.Context().Deadline(); !hasDeadline {
+		return
+	}
+	detached, cancel := context.WithCancel(context.WithoutCancel(c.Context()))
+	context.AfterFunc(c.requestContext, cancel)
+	c.SetContext(detached)
+}
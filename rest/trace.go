@@ -0,0 +1,17 @@
+package rest
+
+// Tracer 请求追踪钩子，未设置时不产生任何额外开销
+// rest/trace 包提供了兼容 W3C Trace Context 的实现
+type Tracer interface {
+	// StartRequest 在请求开始时调用，handlerName 为路由最终处理器的名称
+	// 返回的 end 会在响应写出后调用
+	StartRequest(ctx *Context, handlerName string) (end func())
+	// StartHandler 在执行链中的每个处理器（包括中间件）执行前调用
+	// 返回的 end 会在该处理器返回后调用
+	StartHandler(ctx *Context, handlerName string) (end func())
+}
+
+// SetTracer 设置请求追踪器，传入 nil 关闭追踪
+func (s *Server) SetTracer(tracer Tracer) {
+	s.tracer = tracer
+}
@@ -0,0 +1,138 @@
+// Package trace 为 rest 框架提供兼容 W3C Trace Context 的请求追踪
+//
+// 每个请求会产生一个根 span，执行链中的每个处理器（包括中间件）会产生一个子 span。
+// span 结束后交给 Exporter 处理，默认的 NoopExporter 会丢弃所有 span，
+// 需要接入真实的采集器时只需实现 Exporter 接口。
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// TraceparentHeader W3C Trace Context 请求头
+const TraceparentHeader = "traceparent"
+
+// ErrInvalidTraceparent traceparent 格式错误
+var ErrInvalidTraceparent = errors.New("trace: invalid traceparent")
+
+// TraceID 追踪 ID
+type TraceID [16]byte
+
+// String 返回十六进制表示
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// IsValid 全零的追踪 ID 无效
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+// SpanID span ID
+type SpanID [8]byte
+
+// String 返回十六进制表示
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// IsValid 全零的 span ID 无效
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+// FlagSampled traceparent 中的采样标志位
+const FlagSampled byte = 0x01
+
+// SpanContext 可跨进程传播的 span 标识
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Flags   byte
+	Remote  bool // 是否来自上游服务
+}
+
+// IsValid 追踪 ID 和 span ID 都有效时返回 true
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Traceparent 返回 W3C traceparent 格式的字符串
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, sc.Flags)
+}
+
+// ParseTraceparent 解析 W3C traceparent 请求头
+func ParseTraceparent(value string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	// 版本 ff 无效，版本 00 必须恰好包含 4 段
+	if parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	var sc SpanContext
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	var flags [1]byte
+	if _, err := hex.Decode(flags[:], []byte(parts[3])); err != nil {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	sc.Flags = flags[0]
+	sc.Remote = true
+	if !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	return sc, nil
+}
+
+// spanKey 在 context.Context 中存储当前 span 的键
+type spanKey struct{}
+
+// ContextWithSpan 将 span 存入 context
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext 获取 context 中当前的 span，不存在时返回 nil
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// Inject 将 context 中当前 span 的 traceparent 写入请求头，用于向下游服务传播
+func Inject(ctx context.Context, header http.Header) {
+	if span := SpanFromContext(ctx); span != nil {
+		header.Set(TraceparentHeader, span.SpanContext.Traceparent())
+	}
+}
+
+// newTraceID 生成随机追踪 ID
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
+
+// newSpanID 生成随机 span ID
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
@@ -0,0 +1,52 @@
+package trace
+
+import (
+	"slices"
+	"sync"
+)
+
+// Exporter span 导出器，接入真实的采集器时实现该接口即可
+// Export 在请求处理过程中同步调用，耗时操作应自行异步处理
+type Exporter interface {
+	Export(span *Span)
+}
+
+// NoopExporter 丢弃所有 span
+type NoopExporter struct{}
+
+// Export 实现 Exporter 接口
+func (NoopExporter) Export(*Span) {}
+
+// InMemoryExporter 将 span 保存在内存中，用于测试和调试
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+// NewInMemoryExporter 创建内存导出器
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{
+		spans: make([]*Span, 0),
+	}
+}
+
+// Export 实现 Exporter 接口
+func (e *InMemoryExporter) Export(span *Span) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+// Spans 按结束顺序返回所有已导出的 span
+func (e *InMemoryExporter) Spans() []*Span {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.spans)
+}
+
+// Reset 清空已导出的 span
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = e.spans[:0]
+}
@@ -0,0 +1,144 @@
+package trace
+
+import (
+	"sync"
+	"time"
+
+	"github.com/akagiyui/go-together/rest"
+)
+
+// Span 一次操作的耗时记录
+type Span struct {
+	Name         string
+	SpanContext  SpanContext
+	ParentSpanID SpanID // 根 span 且没有上游时为零值
+	StartTime    time.Time
+	EndTime      time.Time
+
+	mu         sync.Mutex
+	attributes map[string]any
+}
+
+// SetAttribute 设置 span 属性
+func (s *Span) SetAttribute(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attributes[key] = value
+}
+
+// Attributes 返回 span 属性的副本
+func (s *Span) Attributes() map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attributes := make(map[string]any, len(s.attributes))
+	for key, value := range s.attributes {
+		attributes[key] = value
+	}
+	return attributes
+}
+
+// Duration 返回 span 的耗时
+func (s *Span) Duration() time.Duration {
+	return s.EndTime.Sub(s.StartTime)
+}
+
+// 请求 span 使用的属性名，与 OpenTelemetry HTTP 语义约定保持一致
+const (
+	AttributeHTTPMethod     = "http.request.method"
+	AttributeHTTPRoute      = "http.route"
+	AttributeHTTPStatusCode = "http.response.status_code"
+	AttributeURLPath        = "url.path"
+	AttributeHandler        = "rest.handler"
+)
+
+// Tracer 实现 rest.Tracer 接口
+type Tracer struct {
+	exporter Exporter
+}
+
+// New 创建追踪器，exporter 为 nil 时使用 NoopExporter
+//
+// 使用示例:
+//
+//	exporter := trace.NewInMemoryExporter()
+//	server.SetTracer(trace.New(exporter))
+func New(exporter Exporter) *Tracer {
+	if exporter == nil {
+		exporter = NoopExporter{}
+	}
+	return &Tracer{
+		exporter: exporter,
+	}
+}
+
+// start 创建 span，parent 无效时开启新的追踪
+func (t *Tracer) start(name string, parent SpanContext) *Span {
+	span := &Span{
+		Name:       name,
+		StartTime:  time.Now(),
+		attributes: make(map[string]any),
+	}
+	if parent.IsValid() {
+		span.SpanContext = SpanContext{TraceID: parent.TraceID, SpanID: newSpanID(), Flags: parent.Flags}
+		span.ParentSpanID = parent.SpanID
+	} else {
+		span.SpanContext = SpanContext{TraceID: newTraceID(), SpanID: newSpanID(), Flags: FlagSampled}
+	}
+	return span
+}
+
+// end 结束 span 并导出
+func (t *Tracer) end(span *Span) {
+	span.EndTime = time.Now()
+	t.exporter.Export(span)
+}
+
+// StartRequest 实现 rest.Tracer 接口，创建请求的根 span
+// 请求携带合法的 traceparent 时会延续上游的追踪
+func (t *Tracer) StartRequest(ctx *rest.Context, handlerName string) func() {
+	parent, _ := ParseTraceparent(ctx.Request.Header.Get(TraceparentHeader))
+
+	route := ctx.Pattern
+	name := ctx.Method
+	if route != "" {
+		name += " " + route
+	}
+	span := t.start(name, parent)
+	span.SetAttribute(AttributeHTTPMethod, ctx.Method)
+	span.SetAttribute(AttributeURLPath, ctx.Endpoint)
+	if route != "" {
+		span.SetAttribute(AttributeHTTPRoute, route)
+	}
+	span.SetAttribute(AttributeHandler, handlerName)
+
+	// 状态码取自实际写出的响应，与 metrics 一致
+	writer := ctx.StatusWriter()
+	ctx.SetContext(ContextWithSpan(ctx.Context(), span))
+	return func() {
+		span.SetAttribute(AttributeHTTPStatusCode, writer.Status())
+		t.end(span)
+	}
+}
+
+// StartHandler 实现 rest.Tracer 接口，为执行链中的处理器创建子 span
+func (t *Tracer) StartHandler(ctx *rest.Context, handlerName string) func() {
+	parentCtx := ctx.Context()
+	parent := SpanFromContext(parentCtx)
+	if parent == nil {
+		return func() {}
+	}
+
+	span := t.start(handlerName, parent.SpanContext)
+	spanCtx := ContextWithSpan(parentCtx, span)
+	ctx.SetContext(spanCtx)
+	return func() {
+		t.end(span)
+		// 恢复父 span，保证同级处理器的 span 挂在同一个父节点下
+		// 处理器替换过 context 时保留其中的值，只把当前 span 指回父 span
+		if current := ctx.Context(); current == spanCtx {
+			ctx.SetContext(parentCtx)
+		} else {
+			ctx.SetContext(ContextWithSpan(current, parent))
+		}
+	}
+}